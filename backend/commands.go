@@ -0,0 +1,533 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/config"
+	"github.com/iraunchy/dyel/backend/internal/handlers"
+	"github.com/iraunchy/dyel/backend/internal/importers"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+	"github.com/iraunchy/dyel/backend/internal/seed"
+)
+
+// app is what a command runs with.
+type app struct {
+	cfg *config.Config
+	db  *gorm.DB // nil for commands with noDB
+	out io.Writer
+}
+
+// runFunc carries out a command with its positional arguments.
+type runFunc func(ctx context.Context, a *app, args []string) error
+
+// command is one dyel subcommand. setup registers the command's own flags
+// on fs and returns the function that runs it; the config flags are added
+// to fs afterwards, so every command also takes --config and --section.key.
+type command struct {
+	name    string
+	args    string
+	summary string
+	setup   func(fs *flag.FlagSet) runFunc
+	// noDB commands run without connecting to the database.
+	noDB bool
+	// migrate brings the schema up to date before the command runs. Other
+	// commands expect it to be current already.
+	migrate bool
+	// replicas sends reads to DATABASE_REPLICA_URLS. Other commands use the
+	// primary alone so they read their own writes.
+	replicas bool
+}
+
+// commands lists every subcommand; the first is the default.
+var commands = []command{
+	{name: "serve", summary: "Run the API server (the default)", setup: noFlags(runServe), replicas: true, migrate: true},
+	{name: "migrate", summary: "Create or update the database schema", setup: noFlags(runMigrate), migrate: true},
+	{name: "import", args: "<file|->", summary: "Create programs from JSON or NDJSON, or a Strong, Hevy or spreadsheet CSV", setup: setupImport},
+	{name: "export", args: "<id> | --all", summary: "Write one program as JSON, or every program as NDJSON", setup: setupExport},
+	{name: "programs list", summary: "List programs", setup: noFlags(runProgramsList)},
+	{name: "user create", summary: "Register a user ID and its training maxes", setup: setupUserCreate},
+	{name: "seed", summary: "Add generated demo programs", setup: setupSeed},
+	{name: "config print", summary: "Print the effective configuration with secrets redacted", setup: noFlags(runConfigPrint), noDB: true},
+}
+
+func noFlags(run runFunc) func(*flag.FlagSet) runFunc {
+	return func(*flag.FlagSet) runFunc { return run }
+}
+
+// run finds the command named at the start of args, loads the
+// configuration and runs it. With no command, or only flags, it serves.
+func run(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		printUsage(out)
+		return nil
+	}
+	cmd, args, err := findCommand(args)
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("dyel "+cmd.name, flag.ContinueOnError)
+	exec := cmd.setup(fs)
+	own := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	fs.VisitAll(func(f *flag.Flag) { own.Var(f.Value, f.Name, f.Usage) })
+
+	cfg, rest, err := config.LoadCommand(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		cmd.printUsage(out, own)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(cfg.Logging.Logger(os.Stderr))
+	// Whatever still writes through the log package, such as net/http's
+	// connection errors, is a problem report, not chatter.
+	slog.SetLogLoggerLevel(slog.LevelWarn)
+
+	a := &app{cfg: cfg, out: out}
+	if !cmd.noDB {
+		opts := cfg.Database.Options()
+		if !cmd.replicas {
+			opts.Replicas = nil
+		}
+		conn, err := db.Init(ctx, cfg.Database.DSN(), opts)
+		if err != nil {
+			return fmt.Errorf("database init error: %w", err)
+		}
+		if cmd.migrate {
+			if err := db.Migrate(conn); err != nil {
+				return fmt.Errorf("auto-migrate failed: %w", err)
+			}
+		}
+		// GORM warns on stdout by default, which carries command output.
+		a.db = conn.Session(&gorm.Session{Logger: logger.New(
+			log.New(os.Stderr, "\r\n", log.LstdFlags),
+			logger.Config{SlowThreshold: 200 * time.Millisecond, LogLevel: logger.Warn},
+		)})
+	}
+	return exec(ctx, a, rest)
+}
+
+// findCommand matches the leading words of args against commands.
+func findCommand(args []string) (*command, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return &commands[0], args, nil
+	}
+	for i := range commands {
+		words := strings.Fields(commands[i].name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return &commands[i], args[len(words):], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unknown command %q; run \"dyel help\" for the list", args[0])
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: dyel [command] [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", c.name, c.args, c.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Every command also takes --config <file> and a --section.key flag for each
+setting shown by "dyel config print". Run "dyel <command> -h" for its flags.`)
+}
+
+func (c *command) printUsage(w io.Writer, own *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s\n\n%s.\n", strings.TrimSpace("dyel "+c.name+" [flags] "+c.args), c.summary)
+	n := 0
+	own.VisitAll(func(*flag.Flag) { n++ })
+	if n > 0 {
+		fmt.Fprintln(w, "\nFlags:")
+		own.SetOutput(w)
+		own.PrintDefaults()
+	}
+}
+
+func runMigrate(_ context.Context, a *app, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("migrate takes no arguments")
+	}
+	// the migrate flag has already migrated by the time the command runs
+	fmt.Fprintln(a.out, "database schema is up to date")
+	return nil
+}
+
+func setupImport(fs *flag.FlagSet) runFunc {
+	keepIDs := fs.Bool("keep-ids", false, "keep the IDs in the file, e.g. to restore an export into an empty database")
+	format := fs.String("format", "json", "json, or a CSV export: "+strings.Join(importers.Formats, ", "))
+	var opts importers.Options
+	fs.StringVar(&opts.Name, "name", "", "name of the program made from a CSV export")
+	fs.StringVar(&opts.SharedBy, "shared-by", "", "author of the program made from a CSV export")
+	fs.StringVar(&opts.Unit, "unit", "", "unit of weights a CSV export doesn't name (kg or lb)")
+	weeks := fs.Int("weeks", 0, "weeks of a workout history that make up the current routine (default 4)")
+	dryRun := fs.Bool("dry-run", false, "check and print what would be imported without importing it")
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: dyel import [--keep-ids | --format <format> --shared-by <email>] <file|->")
+		}
+		in := io.Reader(os.Stdin)
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		if *format != "json" {
+			opts.Window = time.Duration(*weeks) * 7 * 24 * time.Hour
+			return importCSV(ctx, a, args[0], *format, in, opts, *dryRun)
+		}
+		programs, err := readPrograms(in, *keepIDs, a.cfg.Programs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if len(programs) == 0 {
+			return fmt.Errorf("%s: no programs found", args[0])
+		}
+		if *dryRun {
+			for _, p := range programs {
+				fmt.Fprintln(a.out, p.Name)
+				printDays(a.out, p)
+			}
+			fmt.Fprintf(a.out, "would import %d programs\n", len(programs))
+			return nil
+		}
+		created, err := repos.NewGORMProgramRepo(a.db).CreateMany(ctx, programs)
+		if err != nil {
+			return fmt.Errorf("nothing imported: %w", err)
+		}
+		fmt.Fprintf(a.out, "imported %d programs\n", len(created))
+		return nil
+	}
+}
+
+// importCSV converts a CSV export with importers.Parse, checks the result
+// like POST /programs, size limits included, and creates it, or only
+// prints it on a dry run.
+func importCSV(ctx context.Context, a *app, path, format string, in io.Reader, opts importers.Options, dryRun bool) error {
+	if opts.SharedBy == "" {
+		return fmt.Errorf("--shared-by is required with --format %s", format)
+	}
+	res, err := importers.Parse(format, in, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	p := res.Program
+	err = binding.Validator.ValidateStruct(&handlers.CreateProgramInput{Name: p.Name, SharedBy: p.SharedBy, Days: p.Days})
+	if err == nil {
+		err = db.CheckSize(p.Days, a.cfg.Programs.MaxDays, a.cfg.Programs.MaxExercises)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if dryRun {
+		fmt.Fprintf(a.out, "%s (%d rows, %d workouts)\n", p.Name, res.Rows, res.Workouts)
+		printDays(a.out, p)
+	} else {
+		if _, err := repos.NewGORMProgramRepo(a.db).Create(ctx, p); err != nil {
+			return fmt.Errorf("nothing imported: %w", err)
+		}
+		fmt.Fprintf(a.out, "imported %s as %s\n", p.Name, p.ID)
+	}
+	if len(res.Unmapped) > 0 {
+		fmt.Fprintf(a.out, "kept as written: %s\n", strings.Join(res.Unmapped, ", "))
+	}
+	return nil
+}
+
+// printDays lists p's days and exercises for a dry run.
+func printDays(w io.Writer, p *db.Program) {
+	for _, d := range p.Days {
+		fmt.Fprintf(w, "  %s\n", d.Name)
+		for _, ex := range d.Exercises {
+			line := fmt.Sprintf("%s: %d x %s", ex.Name, ex.Sets, ex.Reps)
+			if ex.Load != nil {
+				line += fmt.Sprintf(" @ %g %s", *ex.Load, ex.LoadUnit)
+			}
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+}
+
+// importItem is a program as written by export or sent to POST /programs.
+type importItem struct {
+	ID string `json:"id"`
+	handlers.CreateProgramInput
+}
+
+// readPrograms decodes every program in r, which may hold JSON objects,
+// arrays of them, or both one after another (as NDJSON does). Each one is
+// checked with the same rules as POST /programs, including the size limits
+// in limits, and every problem is reported before anything is written.
+func readPrograms(r io.Reader, keepIDs bool, limits config.ProgramsConfig) ([]*db.Program, error) {
+	var items []json.RawMessage
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(raw) > 0 && raw[0] == '[' {
+			var list []json.RawMessage
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, err
+			}
+			items = append(items, list...)
+			continue
+		}
+		items = append(items, raw)
+	}
+
+	var programs []*db.Program
+	var problems []error
+	for i, raw := range items {
+		var in importItem
+		err := json.Unmarshal(raw, &in)
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&in.CreateProgramInput)
+		}
+		if err == nil {
+			err = db.CheckSize(in.Days, limits.MaxDays, limits.MaxExercises)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("program %d: %w", i+1, err))
+			continue
+		}
+		p := in.ToModel()
+		if keepIDs {
+			p.ID = in.ID
+		} else {
+			clearIDs(p)
+		}
+		programs = append(programs, p)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return programs, nil
+}
+
+// clearIDs drops the IDs, parent keys and timestamps below p so that the
+// repo assigns new ones.
+func clearIDs(p *db.Program) {
+	p.ID = ""
+	for di := range p.Days {
+		day := &p.Days[di]
+		day.ID, day.ProgramID = "", ""
+		day.CreatedAt, day.UpdatedAt = time.Time{}, time.Time{}
+		for gi := range day.Groups {
+			day.Groups[gi].ID, day.Groups[gi].DayID = "", ""
+		}
+		for ei := range day.Exercises {
+			ex := &day.Exercises[ei]
+			ex.ID, ex.DayID = "", ""
+			ex.CreatedAt, ex.UpdatedAt = time.Time{}, time.Time{}
+			for si := range ex.SetPrescriptions {
+				ex.SetPrescriptions[si].ID, ex.SetPrescriptions[si].ExerciseID = "", ""
+			}
+		}
+	}
+}
+
+func setupExport(fs *flag.FlagSet) runFunc {
+	all := fs.Bool("all", false, "export every program outside the trash as NDJSON")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	return func(ctx context.Context, a *app, args []string) (err error) {
+		if *all == (len(args) == 1) || len(args) > 1 {
+			return fmt.Errorf("usage: dyel export [-o file] <id> | --all")
+		}
+		out := a.out
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if cerr := f.Close(); err == nil {
+					err = cerr
+				}
+			}()
+			out = f
+		}
+		w := bufio.NewWriter(out)
+		enc := json.NewEncoder(w)
+		repo := repos.NewGORMProgramRepo(a.db)
+
+		n := 0
+		if *all {
+			err = repo.Each(ctx, 100, func(p *db.Program) error {
+				n++
+				return enc.Encode(p)
+			})
+		} else {
+			var p *db.Program
+			p, err = repo.Get(ctx, args[0])
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("program %s not found", args[0])
+			}
+			if err == nil {
+				n = 1
+				enc.SetIndent("", "  ")
+				err = enc.Encode(p)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if *output != "" {
+			fmt.Fprintf(a.out, "exported %d programs to %s\n", n, *output)
+		}
+		return nil
+	}
+}
+
+func runProgramsList(ctx context.Context, a *app, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("programs list takes no arguments")
+	}
+	programs, err := repos.NewGORMProgramRepo(a.db).List(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSHARED BY\tDAYS\tVERSION\tUPDATED")
+	for _, p := range programs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n",
+			p.ID, p.Name, p.SharedBy, len(p.Days), p.Version, p.UpdatedAt.UTC().Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+// Users have no table of their own: a user is the ID that training maxes,
+// ?user_id= and X-User-ID refer to. user create picks that ID and stores the
+// user's training maxes.
+func setupUserCreate(fs *flag.FlagSet) runFunc {
+	id := fs.String("id", "", "user ID; a new UUID when empty")
+	var maxes []string
+	fs.Func("max", `a training max as "exercise=value[kg|lb]", e.g. "Back Squat=140kg"; repeatable`,
+		func(s string) error {
+			maxes = append(maxes, s)
+			return nil
+		})
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("usage: dyel user create [--id ID] [--max exercise=value]...")
+		}
+		userID := *id
+		if userID == "" {
+			userID = uuid.NewString()
+		}
+		parsed := make([]*db.TrainingMax, len(maxes))
+		for i, s := range maxes {
+			m, err := parseTrainingMax(s)
+			if err != nil {
+				return fmt.Errorf("--max %q: %w", s, err)
+			}
+			m.UserID = userID
+			parsed[i] = m
+		}
+
+		repo := repos.NewGORMTrainingMaxRepo(a.db)
+		fmt.Fprintln(a.out, userID)
+		for _, m := range parsed {
+			saved, err := repo.Upsert(ctx, m)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(a.out, "  %s: %g %s\n", saved.Exercise, saved.Value, saved.Unit)
+		}
+		return nil
+	}
+}
+
+// parseTrainingMax parses "exercise=value" with an optional kg or lb
+// suffix on the value; kg is assumed.
+func parseTrainingMax(s string) (*db.TrainingMax, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 1 {
+		return nil, fmt.Errorf("want exercise=value")
+	}
+	exercise, value := strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	unit := db.UnitKg
+	for _, u := range []string{db.UnitKg, db.UnitLb} {
+		if v, ok := strings.CutSuffix(value, u); ok {
+			value, unit = strings.TrimSpace(v), u
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("%q is not a positive load", value)
+	}
+	if n > 1000 {
+		return nil, fmt.Errorf("%q is over the 1000 limit", value)
+	}
+	return &db.TrainingMax{Exercise: exercise, Value: n, Unit: unit}, nil
+}
+
+func setupSeed(fs *flag.FlagSet) runFunc {
+	var opts seed.Options
+	fs.Uint64Var(&opts.Seed, "seed", 1, "random seed; the same seed adds the same programs")
+	fs.IntVar(&opts.Programs, "programs", seed.DefaultPrograms, "how many programs to add")
+	fs.Func("templates", "comma-separated templates to use ("+strings.Join(seed.Templates(), ", ")+"); all when empty",
+		func(s string) error {
+			for _, t := range strings.Split(s, ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					opts.Templates = append(opts.Templates, t)
+				}
+			}
+			return nil
+		})
+	fs.BoolVar(&opts.History, "history", false, "also edit some programs and trash a few")
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) > 0 {
+			return fmt.Errorf("seed takes no arguments")
+		}
+		if opts.Programs < 1 {
+			return fmt.Errorf("--programs must be positive")
+		}
+		sum, err := seed.Run(ctx, repos.NewGORMProgramRepo(a.db), opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.out, "created %d programs, updated %d times, trashed %d\n", sum.Created, sum.Updated, sum.Trashed)
+		return nil
+	}
+}
+
+func runConfigPrint(_ context.Context, a *app, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("config print takes no arguments")
+	}
+	return a.cfg.Print(a.out)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/config"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+)
+
+// runWith runs the named command against dbConn, parsing args the way run
+// does but without connecting to Postgres.
+func runWith(t *testing.T, dbConn *gorm.DB, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("DATABASE_URL", "postgres://localhost/dyel")
+	cmd, args, err := findCommand(args)
+	require.NoError(t, err)
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	exec := cmd.setup(fs)
+	cfg, rest, err := config.LoadCommand(fs, args)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	err = exec(context.Background(), &app{cfg: cfg, db: dbConn, out: &out}, rest)
+	return out.String(), err
+}
+
+func TestFindCommand(t *testing.T) {
+	cmd, rest, err := findCommand(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "serve", cmd.name)
+	assert.Empty(t, rest)
+
+	cmd, rest, err = findCommand([]string{"--server.port=9000"})
+	require.NoError(t, err)
+	assert.Equal(t, "serve", cmd.name, "bare flags still start the server")
+	assert.Equal(t, []string{"--server.port=9000"}, rest)
+
+	cmd, rest, err = findCommand([]string{"programs", "list", "-o", "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "programs list", cmd.name)
+	assert.Equal(t, []string{"-o", "x"}, rest)
+
+	_, _, err = findCommand([]string{"programs"})
+	assert.ErrorContains(t, err, `unknown command "programs"`)
+}
+
+func TestImportExport(t *testing.T) {
+	dbConn, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbConn))
+	dir := t.TempDir()
+
+	// an array and a lone object in one file
+	in := filepath.Join(dir, "in.json")
+	require.NoError(t, os.WriteFile(in, []byte(`[
+		{"name":"PPL","shared_by":"a@example.com","days":[{"name":"Push","exercises":[{"name":"Bench","sets":3,"reps":"5"}]}]},
+		{"name":"5x5","shared_by":"a@example.com","days":[]}
+	]
+	{"name":"GZCLP","shared_by":"b@example.com","days":[{"name":"T1"}]}`), 0o600))
+	out, err := runWith(t, dbConn, "import", in)
+	require.NoError(t, err)
+	assert.Equal(t, "imported 3 programs\n", out)
+
+	// invalid items are all reported and nothing is written
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte(`{"name":"x"} {"shared_by":"y","days":[]}`), 0o600))
+	_, err = runWith(t, dbConn, "import", bad)
+	assert.ErrorContains(t, err, "program 1:")
+	assert.ErrorContains(t, err, "program 2:")
+
+	out, err = runWith(t, dbConn, "programs", "list")
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 4)
+	assert.Contains(t, out, "GZCLP")
+
+	dump := filepath.Join(dir, "dump.ndjson")
+	out, err = runWith(t, dbConn, "export", "--all", "-o", dump)
+	require.NoError(t, err)
+	assert.Equal(t, "exported 3 programs to "+dump+"\n", out)
+
+	// importing an export again copies the programs under new IDs
+	_, err = runWith(t, dbConn, "import", dump)
+	require.NoError(t, err)
+	list, err := repos.NewGORMProgramRepo(dbConn).List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, list, 6)
+
+	// --keep-ids clashes with the originals, so the import is all or nothing
+	_, err = runWith(t, dbConn, "import", "--keep-ids", dump)
+	assert.ErrorContains(t, err, "nothing imported")
+
+	out, err = runWith(t, dbConn, "export", list[0].ID)
+	require.NoError(t, err)
+	assert.Contains(t, out, `"id": "`+list[0].ID+`"`)
+	_, err = runWith(t, dbConn, "export", "missing")
+	assert.ErrorContains(t, err, "program missing not found")
+	_, err = runWith(t, dbConn, "export")
+	assert.ErrorContains(t, err, "usage:")
+}
+
+func TestUserCreate(t *testing.T) {
+	dbConn, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbConn))
+
+	out, err := runWith(t, dbConn, "user", "create", "--id", "lifter-1",
+		"--max", "Back Squat=140kg", "--max", "Bench Press = 225 lb")
+	require.NoError(t, err)
+	assert.Equal(t, "lifter-1\n  back squat: 140 kg\n  bench press: 225 lb\n", out)
+
+	maxes, err := repos.NewGORMTrainingMaxRepo(dbConn).List(context.Background(), "lifter-1")
+	require.NoError(t, err)
+	assert.Len(t, maxes, 2)
+
+	_, err = runWith(t, dbConn, "user", "create", "--max", "Deadlift=heavy")
+	assert.ErrorContains(t, err, `"heavy" is not a positive load`)
+}
+
+func TestImportCSV(t *testing.T) {
+	dbConn, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbConn))
+	in := filepath.Join(t.TempDir(), "sheet.csv")
+	require.NoError(t, os.WriteFile(in, []byte("day,exercise,sets,reps,load,unit\n"+
+		"A,Squat (Barbell),5,5,100,kg\nA,Zercher Squat,3,8,,\n"), 0o600))
+
+	out, err := runWith(t, dbConn, "import", "--format", "generic", "--shared-by", "a@example.com", "--dry-run", in)
+	require.NoError(t, err)
+	assert.Equal(t, "Imported spreadsheet (2 rows, 0 workouts)\n  A\n    Back Squat: 5 x 5 @ 100 kg\n"+
+		"    Zercher Squat: 3 x 8\nkept as written: Zercher Squat\n", out)
+	list, err := repos.NewGORMProgramRepo(dbConn).List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	out, err = runWith(t, dbConn, "import", "--format", "generic", "--shared-by", "a@example.com", "--name", "Mine", in)
+	require.NoError(t, err)
+	assert.Contains(t, out, "imported Mine as ")
+
+	_, err = runWith(t, dbConn, "import", "--format", "hevy", in)
+	assert.ErrorContains(t, err, "--shared-by is required")
+
+	_, err = runWith(t, dbConn, "import", "--format", "generic", "--shared-by", "a@example.com",
+		"--programs.max_exercises=1", in)
+	assert.ErrorContains(t, err, "exercises")
+}
+
+func TestImportSizeLimits(t *testing.T) {
+	dbConn, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbConn))
+	in := filepath.Join(t.TempDir(), "programs.json")
+	require.NoError(t, os.WriteFile(in, []byte(`{"name":"Big","shared_by":"a@example.com","days":[`+
+		`{"name":"A","exercises":[{"name":"Squat","sets":5,"reps":"5"}]},`+
+		`{"name":"B","exercises":[{"name":"Bench","sets":5,"reps":"5"}]}]}`), 0o600))
+
+	_, err = runWith(t, dbConn, "import", "--programs.max_days=1", in)
+	assert.ErrorContains(t, err, "days")
+	list, err := repos.NewGORMProgramRepo(dbConn).List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	out, err := runWith(t, dbConn, "import", "--dry-run", in)
+	require.NoError(t, err)
+	assert.Equal(t, "Big\n  A\n    Squat: 5 x 5\n  B\n    Bench: 5 x 5\nwould import 1 programs\n", out)
+	list, err = repos.NewGORMProgramRepo(dbConn).List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, list, "a dry run writes nothing")
+
+	_, err = runWith(t, dbConn, "import", in)
+	require.NoError(t, err)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Exercise group types.
+const (
+	GroupStraight = "straight"
+	GroupSuperset = "superset"
+	GroupGiantSet = "giant_set"
+	GroupCircuit  = "circuit"
+	GroupEMOM     = "emom"
+	GroupAMRAP    = "amrap"
+)
+
+// ExerciseGroup clusters exercises of a Day that are performed together,
+// such as an A1/A2 superset or a 3-round circuit. Exercises join a group by
+// setting their Group to its Label. Rounds and RestBetweenRounds apply to the
+// group as a whole; TimeCap bounds EMOM and AMRAP blocks.
+type ExerciseGroup struct {
+	ID                string    `gorm:"type:text;primaryKey" json:"id"`
+	DayID             string    `gorm:"not null;index" json:"day_id"`
+	Label             string    `gorm:"not null" json:"label" binding:"required,max=16"`
+	Type              string    `gorm:"not null" json:"type" binding:"required,oneof=straight superset giant_set circuit emom amrap"`
+	Rounds            int       `json:"rounds" binding:"min=0"`
+	RestBetweenRounds string    `json:"rest_between_rounds"`
+	TimeCap           string    `json:"time_cap"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ValidateGroups checks that group labels are unique within the day and
+// that every grouped exercise names one of them.
+func (d Day) ValidateGroups() error {
+	labels := make(map[string]bool, len(d.Groups))
+	for _, g := range d.Groups {
+		if labels[g.Label] {
+			return fmt.Errorf("day %q: duplicate group label %q", d.Name, g.Label)
+		}
+		labels[g.Label] = true
+	}
+	for _, ex := range d.Exercises {
+		if ex.Group != "" && !labels[ex.Group] {
+			return fmt.Errorf("day %q: exercise %q refers to unknown group %q", d.Name, ex.Name, ex.Group)
+		}
+	}
+	return nil
+}
+
+// GroupFor returns the group an exercise belongs to, or nil when it is
+// done as straight sets.
+func (d Day) GroupFor(ex Exercise) *ExerciseGroup {
+	if ex.Group == "" {
+		return nil
+	}
+	for i := range d.Groups {
+		if d.Groups[i].Label == ex.Group {
+			return &d.Groups[i]
+		}
+	}
+	return nil
+}
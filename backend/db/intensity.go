@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// Load units.
+const (
+	UnitKg = "kg"
+	UnitLb = "lb"
+)
+
+// KgPerLb is the exact number of kilograms in a pound.
+const KgPerLb = 0.45359237
+
+// tempoPattern matches eccentric-pause-concentric-pause notation such as
+// "3-1-1-0"; X marks an explosive phase.
+var tempoPattern = regexp.MustCompile(`^[0-9X]-[0-9X]-[0-9X]-[0-9X]$`)
+
+// Intensity is the optional load prescription shared by exercises and
+// individual sets. A load is either absolute (Load in LoadUnit) or relative
+// (PercentTM of the lifter's training max); effort is either RPE or RIR.
+// Resolved is filled in on fetch, never stored.
+type Intensity struct {
+	Load      *float64  `json:"load" binding:"omitempty,gt=0,lte=1000"`
+	LoadUnit  string    `json:"load_unit" binding:"omitempty,oneof=kg lb"`
+	PercentTM *float64  `json:"percent_tm" binding:"omitempty,gt=0,lte=150"`
+	RPE       *float64  `json:"rpe" binding:"omitempty,min=1,max=10"`
+	RIR       *int      `json:"rir" binding:"omitempty,min=0,max=10"`
+	Tempo     string    `json:"tempo"`
+	Resolved  *Resolved `gorm:"-" json:"resolved,omitempty"`
+}
+
+// Resolved is a percentage prescription worked out against a training max.
+type Resolved struct {
+	Load        float64 `json:"load"`
+	LoadUnit    string  `json:"load_unit"`
+	TrainingMax float64 `json:"training_max"`
+}
+
+// Validate checks the rules that span more than one field.
+func (i Intensity) Validate() error {
+	switch {
+	case i.Load != nil && i.LoadUnit == "":
+		return fmt.Errorf("load_unit is required with load")
+	case i.Load != nil && i.PercentTM != nil:
+		return fmt.Errorf("load and percent_tm are mutually exclusive")
+	case i.RPE != nil && i.RIR != nil:
+		return fmt.Errorf("rpe and rir are mutually exclusive")
+	case i.Tempo != "" && !tempoPattern.MatchString(i.Tempo):
+		return fmt.Errorf("tempo %q is not in the form 3-1-1-0", i.Tempo)
+	}
+	return nil
+}
+
+// ConvertTo rewrites an absolute load in unit. Relative and unitless
+// prescriptions are left alone.
+func (i *Intensity) ConvertTo(unit string) {
+	if i.Load != nil && i.LoadUnit != "" && i.LoadUnit != unit {
+		v := ConvertLoad(*i.Load, i.LoadUnit, unit)
+		i.Load = &v
+		i.LoadUnit = unit
+	}
+	if i.Resolved != nil && i.Resolved.LoadUnit != unit {
+		i.Resolved.Load = ConvertLoad(i.Resolved.Load, i.Resolved.LoadUnit, unit)
+		i.Resolved.TrainingMax = ConvertLoad(i.Resolved.TrainingMax, i.Resolved.LoadUnit, unit)
+		i.Resolved.LoadUnit = unit
+	}
+}
+
+// ConvertLoad converts v from one unit to another, rounded to 0.01.
+func ConvertLoad(v float64, from, to string) float64 {
+	switch {
+	case from == UnitLb && to == UnitKg:
+		v *= KgPerLb
+	case from == UnitKg && to == UnitLb:
+		v /= KgPerLb
+	}
+	return math.Round(v*100) / 100
+}
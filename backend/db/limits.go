@@ -0,0 +1,22 @@
+package db
+
+import "fmt"
+
+// CheckSize reports an error when days number more than maxDays or hold
+// more than maxExercises exercises between them. A limit of zero or less
+// is no limit.
+func CheckSize(days []Day, maxDays, maxExercises int) error {
+	if maxDays > 0 && len(days) > maxDays {
+		return fmt.Errorf("program has %d days; the limit is %d", len(days), maxDays)
+	}
+	if maxExercises > 0 {
+		n := 0
+		for _, d := range days {
+			n += len(d.Exercises)
+		}
+		if n > maxExercises {
+			return fmt.Errorf("program has %d exercises; the limit is %d", n, maxExercises)
+		}
+	}
+	return nil
+}
@@ -2,36 +2,50 @@ package db
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
-// Exercise represents one movement in a Day.
+// Exercise represents one movement in a Day. When SetPrescriptions is
+// given, Sets and Reps are derived from it.
 type Exercise struct {
-	ID        string    `gorm:"type:text;primaryKey" json:"id"`
-	DayID     string    `gorm:"not null;index" json:"day_id"`
-	Name      string    `json:"name"`
-	Sets      int       `json:"sets"`
-	Reps      string    `json:"reps"`
-	Rest      string    `json:"rest"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID    string `gorm:"type:text;primaryKey" json:"id"`
+	DayID string `gorm:"not null;index" json:"day_id"`
+	Name  string `json:"name"`
+	Sets  int    `json:"sets"`
+	Reps  string `json:"reps"`
+	Rest  string `json:"rest"`
+	Intensity
+	// Group is the Label of the ExerciseGroup this exercise belongs to, if
+	// any; ungrouped exercises are done as straight sets.
+	Group            string            `json:"group"`
+	SetPrescriptions []SetPrescription `gorm:"constraint:OnDelete:CASCADE" json:"set_prescriptions" binding:"dive"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
 }
 
 // Day is one day in a Program, holding many Exercises.
 type Day struct {
-	ID        string     `gorm:"type:text;primaryKey" json:"id"`
-	ProgramID string     `gorm:"not null;index" json:"program_id"`
-	Name      string     `json:"name"`
-	Exercises []Exercise `gorm:"constraint:OnDelete:CASCADE" json:"exercises"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID        string          `gorm:"type:text;primaryKey" json:"id"`
+	ProgramID string          `gorm:"not null;index" json:"program_id"`
+	Name      string          `json:"name"`
+	Groups    []ExerciseGroup `gorm:"constraint:OnDelete:CASCADE" json:"groups" binding:"dive"`
+	Exercises []Exercise      `gorm:"constraint:OnDelete:CASCADE" json:"exercises" binding:"dive"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
 }
 
-// Program is a collection of Days, identified by a UUID.
+// Program is a collection of Days, identified by a UUID. Version starts at 1
+// and is bumped on every update; it backs the ETag used for optimistic
+// concurrency. A set DeletedAt moves the program, and with it its days and
+// exercises, to the trash until it is restored or purged.
 type Program struct {
-	ID        string    `gorm:"type:text;primaryKey" json:"id"`
-	Name      string    `json:"name"`
-	SharedBy  string    `json:"shared_by"`
-	Days      []Day     `gorm:"constraint:OnDelete:CASCADE" json:"days"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string         `gorm:"type:text;primaryKey" json:"id"`
+	Name      string         `json:"name"`
+	SharedBy  string         `json:"shared_by"`
+	Version   int            `gorm:"not null;default:1" json:"version"`
+	Days      []Day          `gorm:"constraint:OnDelete:CASCADE" json:"days"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 }
@@ -0,0 +1,23 @@
+package db
+
+import "time"
+
+// Plate is a denomination of plate and how many of them there are in total;
+// they are loaded in pairs, one on each side of the bar.
+type Plate struct {
+	Weight float64 `json:"weight" binding:"gt=0,lte=100"`
+	Count  int     `json:"count" binding:"min=2,max=100"`
+}
+
+// PlateInventory is the bar and plates available to a user or at a gym,
+// all in Unit. Owner is the user or gym it belongs to.
+type PlateInventory struct {
+	ID        string    `gorm:"type:text;primaryKey" json:"id"`
+	Owner     string    `gorm:"not null;index" json:"owner"`
+	Name      string    `gorm:"not null" json:"name"`
+	Unit      string    `gorm:"not null" json:"unit"`
+	Bar       float64   `gorm:"not null" json:"bar"`
+	Plates    []Plate   `gorm:"serializer:json" json:"plates"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// replicaPolicy spreads reads over the replicas that answered their last
+// health check, and sends them to the primary when none did.
+type replicaPolicy struct {
+	primary gorm.ConnPool
+
+	mu   sync.RWMutex
+	down map[gorm.ConnPool]bool
+	next atomic.Uint64
+}
+
+func newReplicaPolicy(primary gorm.ConnPool) *replicaPolicy {
+	return &replicaPolicy{primary: primary, down: map[gorm.ConnPool]bool{}}
+}
+
+// Resolve implements dbresolver.Policy.
+func (p *replicaPolicy) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	start := p.next.Add(1)
+	for i := range pools {
+		pool := pools[(start+uint64(i))%uint64(len(pools))]
+		if !p.down[pool] {
+			return pool
+		}
+	}
+	return p.primary
+}
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// isDown reports whether pool failed its last health check or query.
+func (p *replicaPolicy) isDown(pool gorm.ConnPool) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.down[pool]
+}
+
+// mark records whether replica n (counting from 1) is down, logging
+// changes.
+func (p *replicaPolicy) mark(pool gorm.ConnPool, n int, err error) {
+	p.mu.Lock()
+	was := p.down[pool]
+	p.down[pool] = err != nil
+	p.mu.Unlock()
+
+	switch {
+	case err != nil && !was:
+		slog.Warn("db: replica is down, reading from the primary instead", "replica", n, "err", err)
+	case err == nil && was:
+		slog.Info("db: replica is back", "replica", n)
+	}
+}
+
+// check pings every replica and records which ones are down.
+func (p *replicaPolicy) check(ctx context.Context, replicas []gorm.ConnPool) {
+	for i, pool := range replicas {
+		pp, ok := pool.(pinger)
+		if !ok {
+			continue
+		}
+		pctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		err := pp.PingContext(pctx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		p.mark(pool, i+1, err)
+	}
+}
+
+// watch runs check every interval until ctx is done.
+func (p *replicaPolicy) watch(ctx context.Context, replicas []gorm.ConnPool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.check(ctx, replicas)
+		}
+	}
+}
+
+// connectionLost reports whether err means the server couldn't be reached
+// or dropped the connection, rather than that the statement failed.
+func connectionLost(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// failover is the pool of one replica. Between health checks, a query that
+// finds the replica unreachable marks it down and runs again on the
+// primary, so a replica outage costs callers one slow read, not an error.
+// Only reads reach replicas, so retrying is safe.
+type failover struct {
+	gorm.ConnPool
+	n      int
+	policy *replicaPolicy
+}
+
+// lost marks the replica down and reports true when err is a lost
+// connection the caller is still waiting on.
+func (f *failover) lost(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || !connectionLost(err) {
+		return false
+	}
+	f.policy.mark(f, f.n, err)
+	return true
+}
+
+func (f *failover) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if !f.policy.isDown(f) {
+		stmt, err := f.ConnPool.PrepareContext(ctx, query)
+		if err == nil || !f.lost(ctx, err) {
+			return stmt, err
+		}
+	}
+	return f.policy.primary.PrepareContext(ctx, query)
+}
+
+func (f *failover) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if !f.policy.isDown(f) {
+		res, err := f.ConnPool.ExecContext(ctx, query, args...)
+		if err == nil || !f.lost(ctx, err) {
+			return res, err
+		}
+	}
+	return f.policy.primary.ExecContext(ctx, query, args...)
+}
+
+func (f *failover) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if !f.policy.isDown(f) {
+		rows, err := f.ConnPool.QueryContext(ctx, query, args...)
+		if err == nil || !f.lost(ctx, err) {
+			return rows, err
+		}
+	}
+	return f.policy.primary.QueryContext(ctx, query, args...)
+}
+
+func (f *failover) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if !f.policy.isDown(f) {
+		row := f.ConnPool.QueryRowContext(ctx, query, args...)
+		if err := row.Err(); err == nil || !f.lost(ctx, err) {
+			return row
+		}
+	}
+	return f.policy.primary.QueryRowContext(ctx, query, args...)
+}
+
+func (f *failover) PingContext(ctx context.Context) error {
+	if pp, ok := f.ConnPool.(pinger); ok {
+		return pp.PingContext(ctx)
+	}
+	return nil
+}
+
+// replicaDialector opens a replica like postgres.Open and wraps its pool
+// in a failover.
+type replicaDialector struct {
+	gorm.Dialector
+	n      int
+	policy *replicaPolicy
+}
+
+func (d replicaDialector) Initialize(db *gorm.DB) error {
+	if err := d.Dialector.Initialize(db); err != nil {
+		return err
+	}
+	db.ConnPool = &failover{ConnPool: db.ConnPool, n: d.n, policy: d.policy}
+	return nil
+}
+
+// useReplicas registers the resolver that sends reads to opts.Replicas,
+// tunes every pool and runs the health checks until ctx is done.
+func useReplicas(ctx context.Context, dbConn *gorm.DB, primary *sql.DB, opts Options) error {
+	policy := newReplicaPolicy(primary)
+	var replicas []gorm.Dialector
+	for i, dsn := range opts.Replicas {
+		replicas = append(replicas, replicaDialector{Dialector: postgres.Open(dsn), n: i + 1, policy: policy})
+	}
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: replicas, Policy: policy})
+	if err := dbConn.Use(resolver); err != nil {
+		return err
+	}
+
+	var pools []gorm.ConnPool
+	if err := resolver.Call(func(pool gorm.ConnPool) error {
+		if f, ok := pool.(*failover); ok {
+			pools = append(pools, f)
+			pool = f.ConnPool
+		}
+		if db, ok := pool.(*sql.DB); ok {
+			tunePool(db, opts)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	interval := opts.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	policy.check(ctx, pools)
+	go policy.watch(ctx, pools, interval)
+	return nil
+}
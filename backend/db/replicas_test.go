@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type fakePool struct {
+	gorm.ConnPool
+	name string
+	err  error
+}
+
+func (f *fakePool) PingContext(context.Context) error { return f.err }
+
+func TestReplicaPolicyFallsBackToPrimary(t *testing.T) {
+	primary := &fakePool{name: "primary"}
+	a, b := &fakePool{name: "a"}, &fakePool{name: "b"}
+	replicas := []gorm.ConnPool{a, b}
+	p := newReplicaPolicy(primary)
+
+	seen := map[gorm.ConnPool]int{}
+	for i := 0; i < 4; i++ {
+		seen[p.Resolve(replicas)]++
+	}
+	assert.Equal(t, map[gorm.ConnPool]int{a: 2, b: 2}, seen, "reads alternate between healthy replicas")
+
+	a.err = errors.New("connection refused")
+	p.check(context.Background(), replicas)
+	for i := 0; i < 3; i++ {
+		assert.Same(t, b, p.Resolve(replicas))
+	}
+
+	b.err = errors.New("connection refused")
+	p.check(context.Background(), replicas)
+	assert.Same(t, primary, p.Resolve(replicas), "no healthy replica")
+
+	a.err = nil
+	p.check(context.Background(), replicas)
+	assert.Same(t, a, p.Resolve(replicas), "a recovered replica is used again")
+}
+
+// queryPool counts queries and fails them with err.
+type queryPool struct {
+	fakePool
+	queries int
+	err     error
+}
+
+func (q *queryPool) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	q.queries++
+	return nil, q.err
+}
+
+func TestFailoverRetriesOnPrimary(t *testing.T) {
+	primary := &queryPool{fakePool: fakePool{name: "primary"}}
+	replica := &queryPool{fakePool: fakePool{name: "replica"}}
+	p := newReplicaPolicy(primary)
+	f := &failover{ConnPool: replica, n: 1, policy: p}
+	ctx := context.Background()
+
+	replica.err = &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	_, err := f.QueryContext(ctx, "SELECT 1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, primary.queries, "retried on the primary")
+	assert.True(t, p.isDown(f))
+	assert.Same(t, primary, p.Resolve([]gorm.ConnPool{f}), "no healthy replica")
+
+	_, err = f.QueryContext(ctx, "SELECT 1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, replica.queries, "a down replica isn't tried")
+	assert.Equal(t, 2, primary.queries)
+
+	p.mark(f, 1, nil)
+	replica.err = errors.New(`relation "programs" does not exist`)
+	_, err = f.QueryContext(ctx, "SELECT 1")
+	assert.Equal(t, replica.err, err, "statement errors are the caller's")
+	assert.Equal(t, 2, primary.queries)
+	assert.False(t, p.isDown(f))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	replica.err = &net.OpError{Op: "read", Net: "tcp", Err: context.Canceled}
+	_, err = f.QueryContext(cancelled, "SELECT 1")
+	assert.Error(t, err, "a cancelled read isn't retried")
+	assert.Equal(t, 2, primary.queries)
+	assert.False(t, p.isDown(f))
+}
+
+func TestWatchStopsWithContext(t *testing.T) {
+	p := newReplicaPolicy(&fakePool{name: "primary"})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.watch(ctx, []gorm.ConnPool{&fakePool{name: "a"}}, time.Millisecond)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watch kept running after its context was cancelled")
+	}
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// Search fields recorded against each indexed row.
+const (
+	SearchFieldProgramName  = "program.name"
+	SearchFieldSharedBy     = "shared_by"
+	SearchFieldDayName      = "day.name"
+	SearchFieldExerciseName = "exercise.name"
+)
+
+// SearchIndexTable is the SQLite FTS5 table mirroring searchable text.
+const SearchIndexTable = "search_index"
+
+// SearchConfig is the Postgres text search configuration for every
+// searchable field and for queries: English, so "pressing" finds "Bench
+// Press" as the porter tokenizer of the SQLite index does. Its parser keeps
+// e-mail addresses in shared_by whole, so they are not stemmed.
+const SearchConfig = "english"
+
+// pgSearchDDL adds generated tsvector columns with GIN indexes. The columns
+// are maintained by Postgres itself, so no triggers are needed.
+var pgSearchDDL = []string{
+	`ALTER TABLE programs ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('` + SearchConfig + `', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('` + SearchConfig + `', coalesce(shared_by, '')), 'C')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_programs_search ON programs USING GIN (search_vector)`,
+	`ALTER TABLE days ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (setweight(to_tsvector('` + SearchConfig + `', coalesce(name, '')), 'B')) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_days_search ON days USING GIN (search_vector)`,
+	`ALTER TABLE exercises ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (setweight(to_tsvector('` + SearchConfig + `', coalesce(name, '')), 'B')) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_exercises_search ON exercises USING GIN (search_vector)`,
+}
+
+// pgSearchTables are the tables with a search_vector column.
+var pgSearchTables = []string{"programs", "days", "exercises"}
+
+// tsConfigs finds the configurations named in a generated column's
+// expression, which Postgres writes as to_tsvector('english'::regconfig, ...).
+var tsConfigs = regexp.MustCompile(`to_tsvector\('(\w+)'`)
+
+// dropStaleSearchColumns drops search_vector columns generated with another
+// configuration than SearchConfig, along with their indexes, so that
+// pgSearchDDL recreates them.
+func dropStaleSearchColumns(dbConn *gorm.DB) error {
+	for _, table := range pgSearchTables {
+		var expr string
+		if err := dbConn.Raw(`SELECT coalesce(generation_expression, '') FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = 'search_vector'`, table).
+			Scan(&expr).Error; err != nil {
+			return err
+		}
+		for _, m := range tsConfigs.FindAllStringSubmatch(expr, -1) {
+			if m[1] != SearchConfig {
+				if err := dbConn.Exec("ALTER TABLE " + table + " DROP COLUMN search_vector").Error; err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// sqliteSearchDDL creates the FTS5 index and the triggers that keep it in
+// step with programs, days and exercises.
+var sqliteSearchDDL = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		program_id UNINDEXED, field UNINDEXED, entity_id UNINDEXED, body,
+		tokenize = 'porter unicode61')`,
+
+	`CREATE TRIGGER IF NOT EXISTS programs_search_ai AFTER INSERT ON programs BEGIN
+		INSERT INTO search_index(program_id, field, entity_id, body) VALUES
+			(new.id, 'program.name', new.id, new.name),
+			(new.id, 'shared_by', new.id, new.shared_by);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS programs_search_au AFTER UPDATE OF name, shared_by ON programs BEGIN
+		DELETE FROM search_index WHERE entity_id = old.id;
+		INSERT INTO search_index(program_id, field, entity_id, body) VALUES
+			(new.id, 'program.name', new.id, new.name),
+			(new.id, 'shared_by', new.id, new.shared_by);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS programs_search_ad AFTER DELETE ON programs BEGIN
+		DELETE FROM search_index WHERE program_id = old.id;
+	END`,
+
+	`CREATE TRIGGER IF NOT EXISTS days_search_ai AFTER INSERT ON days BEGIN
+		INSERT INTO search_index(program_id, field, entity_id, body)
+			VALUES (new.program_id, 'day.name', new.id, new.name);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS days_search_au AFTER UPDATE OF name ON days BEGIN
+		DELETE FROM search_index WHERE entity_id = old.id;
+		INSERT INTO search_index(program_id, field, entity_id, body)
+			VALUES (new.program_id, 'day.name', new.id, new.name);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS days_search_ad AFTER DELETE ON days BEGIN
+		DELETE FROM search_index WHERE entity_id = old.id;
+	END`,
+
+	`CREATE TRIGGER IF NOT EXISTS exercises_search_ai AFTER INSERT ON exercises BEGIN
+		INSERT INTO search_index(program_id, field, entity_id, body)
+			SELECT d.program_id, 'exercise.name', new.id, new.name FROM days d WHERE d.id = new.day_id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS exercises_search_au AFTER UPDATE OF name ON exercises BEGIN
+		DELETE FROM search_index WHERE entity_id = old.id;
+		INSERT INTO search_index(program_id, field, entity_id, body)
+			SELECT d.program_id, 'exercise.name', new.id, new.name FROM days d WHERE d.id = new.day_id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS exercises_search_ad AFTER DELETE ON exercises BEGIN
+		DELETE FROM search_index WHERE entity_id = old.id;
+	END`,
+}
+
+// sqliteSearchBackfill indexes rows that predate the FTS5 table.
+var sqliteSearchBackfill = []string{
+	`INSERT INTO search_index(program_id, field, entity_id, body)
+		SELECT id, 'program.name', id, name FROM programs`,
+	`INSERT INTO search_index(program_id, field, entity_id, body)
+		SELECT id, 'shared_by', id, shared_by FROM programs`,
+	`INSERT INTO search_index(program_id, field, entity_id, body)
+		SELECT program_id, 'day.name', id, name FROM days`,
+	`INSERT INTO search_index(program_id, field, entity_id, body)
+		SELECT d.program_id, 'exercise.name', e.id, e.name FROM exercises e JOIN days d ON d.id = e.day_id`,
+}
+
+// migrateSearch installs the full-text search structures for the dialect.
+// SQLite builds without FTS5 get nothing and search falls back to LIKE.
+func migrateSearch(dbConn *gorm.DB) error {
+	switch dbConn.Dialector.Name() {
+	case "postgres":
+		return dbConn.Transaction(func(tx *gorm.DB) error {
+			if err := dropStaleSearchColumns(tx); err != nil {
+				return err
+			}
+			return execAll(tx, pgSearchDDL)
+		})
+	case "sqlite":
+		if !sqliteHasFTS5(dbConn) {
+			return nil
+		}
+		existed := HasSearchIndex(dbConn)
+		return dbConn.Transaction(func(tx *gorm.DB) error {
+			if err := execAll(tx, sqliteSearchDDL); err != nil {
+				return err
+			}
+			if existed {
+				return nil
+			}
+			return execAll(tx, sqliteSearchBackfill)
+		})
+	}
+	return nil
+}
+
+// HasSearchIndex reports whether the SQLite FTS5 index is present.
+func HasSearchIndex(dbConn *gorm.DB) bool {
+	return dbConn.Dialector.Name() == "sqlite" && dbConn.Migrator().HasTable(SearchIndexTable)
+}
+
+func sqliteHasFTS5(dbConn *gorm.DB) bool {
+	var enabled int
+	if err := dbConn.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error; err != nil {
+		return false
+	}
+	return enabled == 1
+}
+
+func execAll(dbConn *gorm.DB, stmts []string) error {
+	for _, stmt := range stmts {
+		if err := dbConn.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchDDLUsesOneConfig(t *testing.T) {
+	var found int
+	for _, stmt := range pgSearchDDL {
+		for _, m := range tsConfigs.FindAllStringSubmatch(stmt, -1) {
+			assert.Equal(t, SearchConfig, m[1], stmt)
+			found++
+		}
+	}
+	assert.Equal(t, 4, found)
+}
+
+func TestTSConfigsReadsGeneratedColumns(t *testing.T) {
+	// as information_schema reports the column an earlier release made
+	expr := `(setweight(to_tsvector('english'::regconfig, COALESCE(name, ''::text)), 'A'::"char") || ` +
+		`setweight(to_tsvector('simple'::regconfig, COALESCE(shared_by, ''::text)), 'C'::"char"))`
+	var configs []string
+	for _, m := range tsConfigs.FindAllStringSubmatch(expr, -1) {
+		configs = append(configs, m[1])
+	}
+	assert.Equal(t, "english,simple", strings.Join(configs, ","))
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"strings"
+	"time"
+)
+
+// Set prescription types.
+const (
+	SetWarmup  = "warmup"
+	SetWorking = "working"
+	SetDrop    = "drop"
+	SetAMRAP   = "amrap"
+)
+
+// SetPrescription is one set of an Exercise, for pyramids, drop sets,
+// back-off sets and top singles that a single Sets×Reps can't express.
+// Position orders the sets within the exercise.
+type SetPrescription struct {
+	ID         string `gorm:"type:text;primaryKey" json:"id"`
+	ExerciseID string `gorm:"not null;index" json:"exercise_id"`
+	Position   int    `gorm:"not null" json:"position"`
+	Type       string `gorm:"not null" json:"type" binding:"required,oneof=warmup working drop amrap"`
+	Reps       string `json:"reps" binding:"required"`
+	Intensity
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Summarize derives Sets and Reps from the set prescriptions, if any, so
+// clients that only read those keep working. Warm-up sets are not counted;
+// Reps is the common rep count, or each set's reps joined by "/".
+func (e *Exercise) Summarize() {
+	if len(e.SetPrescriptions) == 0 {
+		return
+	}
+	var reps []string
+	for _, s := range e.SetPrescriptions {
+		if s.Type != SetWarmup {
+			reps = append(reps, s.Reps)
+		}
+	}
+	e.Sets = len(reps)
+	e.Reps = ""
+	for _, r := range reps {
+		if r != reps[0] {
+			e.Reps = strings.Join(reps, "/")
+			return
+		}
+	}
+	if len(reps) > 0 {
+		e.Reps = reps[0]
+	}
+}
@@ -1,22 +1,78 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"time"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// Init opens a connection using the provided DSN and runs AutoMigrate.
-func Init(dsn string) (*gorm.DB, error) {
-	dbConn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Options tunes the database/sql connection pools and lists read replicas.
+// Zero pool values keep the database/sql defaults.
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// Replicas are DSNs for read-only copies of the primary. Plain reads
+	// outside a transaction go to a healthy replica; writes, transactions
+	// and reads while every replica is down go to the primary, as does a
+	// read that finds its replica unreachable.
+	Replicas []string
+	// ReplicaCheckInterval is how often replicas are pinged.
+	ReplicaCheckInterval time.Duration
+}
+
+// Init opens a connection using the provided DSN, then routes reads to
+// opts.Replicas and sizes every pool. It leaves the schema alone; Migrate
+// updates it. Replica health checks stop when ctx is done.
+func Init(ctx context.Context, dsn string, opts Options) (*gorm.DB, error) {
+	dbConn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{DisableAutomaticPing: true})
 	if err != nil {
 		return nil, fmt.Errorf("couldn't connect to database: %w", err)
 	}
-	// auto-migrate your models
-	if err := dbConn.AutoMigrate(&Program{}, &Day{}, &Exercise{}); err != nil {
-		return nil, fmt.Errorf("auto-migrate failed: %w", err)
+	sqlDB, err := dbConn.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("couldn't connect to database: %w", err)
 	}
 
+	if len(opts.Replicas) == 0 {
+		tunePool(sqlDB, opts)
+		return dbConn, nil
+	}
+	if err := useReplicas(ctx, dbConn, sqlDB, opts); err != nil {
+		return nil, fmt.Errorf("read replicas: %w", err)
+	}
 	return dbConn, nil
+}
 
+// tunePool applies the pool settings in opts to pool.
+func tunePool(pool *sql.DB, opts Options) {
+	if opts.MaxOpenConns > 0 {
+		pool.SetMaxOpenConns(opts.MaxOpenConns)
+	}
+	if opts.MaxIdleConns > 0 {
+		pool.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+	pool.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	pool.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+}
+
+// Migrate creates or updates the tables for every model, then the
+// dialect-specific full-text search structures.
+func Migrate(dbConn *gorm.DB) error {
+	if err := dbConn.AutoMigrate(
+		&Program{}, &Day{}, &ExerciseGroup{}, &Exercise{}, &SetPrescription{},
+		&TrainingMax{}, &PlateInventory{}, &WebhookEndpoint{}, &WebhookDelivery{},
+	); err != nil {
+		return err
+	}
+	return migrateSearch(dbConn)
 }
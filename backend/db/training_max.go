@@ -0,0 +1,23 @@
+package db
+
+import (
+	"strings"
+	"time"
+)
+
+// TrainingMax is the load a lifter bases percentage prescriptions on for
+// one exercise. Exercise names are matched case-insensitively.
+type TrainingMax struct {
+	ID        string    `gorm:"type:text;primaryKey" json:"id"`
+	UserID    string    `gorm:"not null;uniqueIndex:idx_training_max_user_exercise" json:"user_id"`
+	Exercise  string    `gorm:"not null;uniqueIndex:idx_training_max_user_exercise" json:"exercise"`
+	Value     float64   `gorm:"not null" json:"value"`
+	Unit      string    `gorm:"not null" json:"unit"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ExerciseKey normalises an exercise name for training max lookups.
+func ExerciseKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
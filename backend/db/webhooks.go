@@ -0,0 +1,58 @@
+package db
+
+import (
+	"strings"
+	"time"
+)
+
+// Program lifecycle events that can be delivered to webhook endpoints.
+const (
+	EventProgramCreated  = "program.created"
+	EventProgramUpdated  = "program.updated"
+	EventProgramDeleted  = "program.deleted"
+	EventProgramForked   = "program.forked"
+	EventProgramRestored = "program.restored"
+)
+
+// WebhookEndpoint is a registered receiver of program lifecycle events.
+// Events is a comma-separated list of event names; empty means all events.
+type WebhookEndpoint struct {
+	ID        string    `gorm:"type:text;primaryKey" json:"id"`
+	URL       string    `gorm:"not null" json:"url"`
+	Secret    string    `gorm:"not null" json:"-"`
+	Events    string    `json:"events"`
+	Active    bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one outbox row: a single event destined for a single
+// endpoint. Rows are written in the same transaction as the change that
+// produced them and drained by the webhook worker.
+type WebhookDelivery struct {
+	ID            string     `gorm:"type:text;primaryKey" json:"id"`
+	EndpointID    string     `gorm:"not null;index" json:"endpoint_id"`
+	Event         string     `gorm:"not null" json:"event"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	FailedAt      *time.Time `json:"failed_at"`
+	LastError     string     `json:"last_error"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Subscribes reports whether the endpoint wants the given event.
+func (e WebhookEndpoint) Subscribes(event string) bool {
+	if strings.TrimSpace(e.Events) == "" {
+		return true
+	}
+	for _, name := range strings.Split(e.Events, ",") {
+		name = strings.TrimSpace(name)
+		if name == event || name == "*" {
+			return true
+		}
+	}
+	return false
+}
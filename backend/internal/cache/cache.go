@@ -0,0 +1,31 @@
+// Package cache holds the storage behind read-through caches. Values are
+// opaque bytes so that a networked store such as Redis can stand in for the
+// in-process LRU by implementing Cache with GET, SET PX and DEL.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores values by key for up to a TTL. Implementations must be safe
+// for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key and whether there was one.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key; a ttl of zero means no expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the keys, ignoring any that aren't present.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Stats counts how a cache has been used.
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// Errors counts failed cache calls; reads that fail fall through to the
+	// database.
+	Errors uint64 `json:"errors"`
+	// HitRatio is Hits over Hits+Misses, or zero before any lookup.
+	HitRatio float64 `json:"hit_ratio"`
+}
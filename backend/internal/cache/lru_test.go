@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	c := NewLRU(2)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	_, ok, _ := c.Get(ctx, "a") // a is now the most recent
+	assert.True(t, ok)
+
+	c.Set(ctx, "c", []byte("3"), 0)
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok, "least recently used is evicted")
+	assert.Equal(t, 2, c.Len())
+
+	c.Set(ctx, "c", []byte("4"), time.Minute)
+	v, ok, _ := c.Get(ctx, "c")
+	assert.True(t, ok)
+	assert.Equal(t, "4", string(v), "set replaces")
+
+	now = now.Add(time.Minute)
+	_, ok, _ = c.Get(ctx, "c")
+	assert.False(t, ok, "expired")
+	_, ok, _ = c.Get(ctx, "a")
+	assert.True(t, ok, "no ttl, no expiry")
+
+	c.Delete(ctx, "a", "missing")
+	assert.Equal(t, 0, c.Len())
+}
@@ -2,50 +2,314 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/middleware"
+	"github.com/iraunchy/dyel/backend/internal/warmup"
 )
 
-// Config holds all application settings.
+// Config holds all application settings. Each leaf can come from a YAML or
+// TOML file under its yaml/toml key, from the environment variable in its
+// env tag, or from a --section.key flag; see Load for the precedence.
 type Config struct {
-	Port        string
-	DatabaseURL string
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	Logging   LoggingConfig   `yaml:"logging" toml:"logging"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	Programs  ProgramsConfig  `yaml:"programs" toml:"programs"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	CORS      CORSConfig      `yaml:"cors" toml:"cors"`
+	Security  SecurityConfig  `yaml:"security" toml:"security"`
+	Cache     CacheConfig     `yaml:"cache" toml:"cache"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks" toml:"webhooks"`
+
+	// WarmupTemplates are the built-in warm-up ramps plus those in
+	// Programs.WarmupTemplates, parsed during validation.
+	WarmupTemplates map[string]warmup.Template `yaml:"-" toml:"-"`
+}
+
+// ServerConfig covers the listeners.
+type ServerConfig struct {
+	Port string `yaml:"port" toml:"port" env:"PORT"`
+	// TLSCertFile and TLSKeyFile, when both set, make the server speak HTTPS
+	// on Port; the files are re-read within TLSReloadInterval of changing.
+	TLSCertFile       string   `yaml:"tls_cert_file" toml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile        string   `yaml:"tls_key_file" toml:"tls_key_file" env:"TLS_KEY_FILE"`
+	TLSReloadInterval Duration `yaml:"tls_reload_interval" toml:"tls_reload_interval" env:"TLS_RELOAD_INTERVAL"`
+	// HTTPRedirectPort, with TLS on, runs a plain-HTTP listener that
+	// redirects every request to HTTPS.
+	HTTPRedirectPort string `yaml:"http_redirect_port" toml:"http_redirect_port" env:"HTTP_REDIRECT_PORT"`
+	// MaxBodyBytes caps request bodies under /api/v1.
+	MaxBodyBytes int64 `yaml:"max_body_bytes" toml:"max_body_bytes" env:"MAX_BODY_BYTES"`
+	// PublicURL is where users reach the app, such as
+	// https://dyel.example.com, for links printed on program sheets. When
+	// empty, sheets have no link or QR code.
+	PublicURL string `yaml:"public_url" toml:"public_url" env:"PUBLIC_URL"`
+	// TrustedProxies lists the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For is believed when working out the client IP for
+	// rate limiting. With none, the client IP is the connection's peer.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+// DatabaseConfig names the Postgres server and tunes the connection pool.
+// URL, when set, wins over the individual connection fields.
+type DatabaseConfig struct {
+	URL      string `yaml:"url" toml:"url" env:"DATABASE_URL" secret:"true"`
+	Host     string `yaml:"host" toml:"host" env:"POSTGRES_HOST"`
+	Port     string `yaml:"port" toml:"port" env:"POSTGRES_PORT"`
+	User     string `yaml:"user" toml:"user" env:"POSTGRES_USER"`
+	Password string `yaml:"password" toml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" toml:"name" env:"POSTGRES_DB"`
+	// SSLMode applies when the URL is built from the fields above.
+	SSLMode string `yaml:"ssl_mode" toml:"ssl_mode" env:"DB_SSLMODE"`
+
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	// ConnectTimeout and StatementTimeout are passed to Postgres; zero
+	// leaves them unlimited.
+	ConnectTimeout   Duration `yaml:"connect_timeout" toml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	StatementTimeout Duration `yaml:"statement_timeout" toml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+
+	// ReplicaURLs are read replicas of the primary. Reads outside a
+	// transaction go to whichever answered its last health check, run
+	// every ReplicaCheckInterval, and to the primary when none did.
+	ReplicaURLs          []string `yaml:"replica_urls" toml:"replica_urls" env:"DATABASE_REPLICA_URLS" secret:"true"`
+	ReplicaCheckInterval Duration `yaml:"replica_check_interval" toml:"replica_check_interval" env:"DB_REPLICA_CHECK_INTERVAL"`
+}
+
+// LoggingConfig picks the log level and output format.
+type LoggingConfig struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
+
+// AuthConfig holds the credentials clients may authenticate with. APIKeys
+// are validated and kept out of "config print", but not yet enforced.
+type AuthConfig struct {
+	APIKeys []string `yaml:"api_keys" toml:"api_keys" env:"API_KEYS" secret:"true"`
+}
+
+// ProgramsConfig covers program editing, size limits and the trash.
+type ProgramsConfig struct {
+	// RequireIfMatch makes program writes without If-Match fail with 428.
+	RequireIfMatch bool `yaml:"require_if_match" toml:"require_if_match" env:"REQUIRE_IF_MATCH"`
+	// BatchMaxItems caps the programs in one POST /programs/batch.
+	BatchMaxItems int `yaml:"batch_max_items" toml:"batch_max_items" env:"BATCH_MAX_ITEMS"`
+	// MaxDays and MaxExercises cap program size.
+	MaxDays      int `yaml:"max_days" toml:"max_days" env:"MAX_DAYS_PER_PROGRAM"`
+	MaxExercises int `yaml:"max_exercises" toml:"max_exercises" env:"MAX_EXERCISES_PER_PROGRAM"`
+	// TrashRetention is how long deleted programs stay restorable, and
+	// TrashPurgeInterval how often expired trash is purged.
+	TrashRetention     Duration `yaml:"trash_retention" toml:"trash_retention" env:"TRASH_RETENTION"`
+	TrashPurgeInterval Duration `yaml:"trash_purge_interval" toml:"trash_purge_interval" env:"TRASH_PURGE_INTERVAL"`
+	// WarmupTemplates adds or overrides warm-up ramps by name; in the
+	// environment they are written "name=bar:10,40:5,60:3,80:1;...".
+	WarmupTemplates map[string]string `yaml:"warmup_templates" toml:"warmup_templates" env:"WARMUP_TEMPLATES"`
 }
 
-// Load reads from the environment (or defaults) and constructs
-func Load() (*Config, error) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// RateLimitConfig holds the sustained requests per second allowed per client
+// IP and per X-User-ID, with bursts of up to the matching Burst; a zero rate
+// disables that limit.
+type RateLimitConfig struct {
+	IP        float64 `yaml:"ip_rps" toml:"ip_rps" env:"RATE_LIMIT_IP_RPS"`
+	IPBurst   int     `yaml:"ip_burst" toml:"ip_burst" env:"RATE_LIMIT_IP_BURST"`
+	User      float64 `yaml:"user_rps" toml:"user_rps" env:"RATE_LIMIT_USER_RPS"`
+	UserBurst int     `yaml:"user_burst" toml:"user_burst" env:"RATE_LIMIT_USER_BURST"`
+}
+
+// CORSConfig mirrors middleware.CORSConfig.
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string `yaml:"allowed_methods" toml:"allowed_methods" env:"CORS_ALLOWED_METHODS"`
+	AllowedHeaders   []string `yaml:"allowed_headers" toml:"allowed_headers" env:"CORS_ALLOWED_HEADERS"`
+	ExposedHeaders   []string `yaml:"exposed_headers" toml:"exposed_headers" env:"CORS_EXPOSED_HEADERS"`
+	AllowCredentials bool     `yaml:"allow_credentials" toml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           Duration `yaml:"max_age" toml:"max_age" env:"CORS_MAX_AGE"`
+}
+
+// SecurityConfig mirrors middleware.SecurityConfig. An empty CSP sends no
+// Content-Security-Policy header, and a zero HSTSMaxAge no HSTS.
+type SecurityConfig struct {
+	CSP                 string   `yaml:"content_security_policy" toml:"content_security_policy" env:"CONTENT_SECURITY_POLICY,allowempty"`
+	HSTSMaxAge          Duration `yaml:"hsts_max_age" toml:"hsts_max_age" env:"HSTS_MAX_AGE"`
+	TrustForwardedProto bool     `yaml:"trust_forwarded_proto" toml:"trust_forwarded_proto" env:"TRUST_FORWARDED_PROTO"`
+}
+
+// CacheConfig sizes the in-process program cache. A zero size turns it off.
+type CacheConfig struct {
+	Size int      `yaml:"programs_size" toml:"programs_size" env:"PROGRAM_CACHE_SIZE"`
+	TTL  Duration `yaml:"programs_ttl" toml:"programs_ttl" env:"PROGRAM_CACHE_TTL"`
+}
+
+// WebhooksConfig covers outgoing webhooks. AllowPrivateNetworks lets
+// endpoints be registered, and delivered to, on loopback and private
+// addresses; leave it off wherever untrusted clients can register them.
+type WebhooksConfig struct {
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" toml:"allow_private_networks" env:"WEBHOOKS_ALLOW_PRIVATE_NETWORKS"`
+}
+
+// Defaults returns the settings used when nothing overrides them.
+func Defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			TLSReloadInterval: Duration(time.Minute),
+			MaxBodyBytes:      2 << 20,
+		},
+		Database: DatabaseConfig{
+			Host:            "db", // Docker Compose service name
+			Port:            "5432",
+			SSLMode:         "disable",
+			MaxOpenConns:    20,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration(30 * time.Minute),
+			ConnMaxIdleTime: Duration(5 * time.Minute),
+			ConnectTimeout:  Duration(10 * time.Second),
+
+			ReplicaCheckInterval: Duration(10 * time.Second),
+		},
+		Logging: LoggingConfig{Level: "info", Format: "text"},
+		Programs: ProgramsConfig{
+			BatchMaxItems:      100,
+			MaxDays:            28,
+			MaxExercises:       400,
+			TrashRetention:     Duration(30 * 24 * time.Hour),
+			TrashPurgeInterval: Duration(time.Hour),
+		},
+		RateLimit: RateLimitConfig{IP: 10, IPBurst: 20, User: 5, UserBurst: 10},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{
+				"Content-Type", "If-Match", "If-None-Match", middleware.UserHeader,
+			},
+			ExposedHeaders: []string{"ETag", "Location", "Retry-After"},
+			MaxAge:         Duration(10 * time.Minute),
+		},
+		Security: SecurityConfig{
+			CSP:        middleware.DefaultCSP,
+			HSTSMaxAge: Duration(180 * 24 * time.Hour),
+		},
+		Cache: CacheConfig{Size: 1000, TTL: Duration(time.Minute)},
 	}
+}
 
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		user := os.Getenv("POSTGRES_USER")
-		pass := os.Getenv("POSTGRES_PASSWORD")
-		name := os.Getenv("POSTGRES_DB")
-		host := os.Getenv("POSTGRES_HOST")
-		if host == "" {
-			host = "db" // Docker Compose service name
-		}
-		portDB := os.Getenv("POSTGRES_PORT")
-		if portDB == "" {
-			portDB = "5432"
-		}
+// DSN returns the primary's connection URL, with the configured timeouts
+// added unless the URL already sets them.
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.withTimeouts(d.URL)
+	}
+	params := d.timeouts()
+	params.Set("sslmode", d.SSLMode)
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, d.Port),
+		Path:     "/" + d.Name,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// Options returns the pool settings and replica DSNs for db.Init.
+func (d DatabaseConfig) Options() db.Options {
+	replicas := make([]string, len(d.ReplicaURLs))
+	for i, r := range d.ReplicaURLs {
+		replicas[i] = d.withTimeouts(r)
+	}
+	return db.Options{
+		MaxOpenConns:         d.MaxOpenConns,
+		MaxIdleConns:         d.MaxIdleConns,
+		ConnMaxLifetime:      time.Duration(d.ConnMaxLifetime),
+		ConnMaxIdleTime:      time.Duration(d.ConnMaxIdleTime),
+		Replicas:             replicas,
+		ReplicaCheckInterval: time.Duration(d.ReplicaCheckInterval),
+	}
+}
 
-		if user == "" || pass == "" || name == "" {
-			return nil, fmt.Errorf(
-				"either DATABASE_URL or all POSTGRES_USER, POSTGRES_PASSWORD, POSTGRES_DB must be set",
-			)
+// timeouts returns the connection parameters for the configured timeouts.
+func (d DatabaseConfig) timeouts() url.Values {
+	params := url.Values{}
+	if d.ConnectTimeout > 0 {
+		secs := (time.Duration(d.ConnectTimeout) + time.Second - 1) / time.Second
+		params.Set("connect_timeout", strconv.Itoa(int(secs)))
+	}
+	if d.StatementTimeout > 0 {
+		params.Set("statement_timeout", strconv.FormatInt(time.Duration(d.StatementTimeout).Milliseconds(), 10))
+	}
+	return params
+}
+
+// withTimeouts adds the timeouts to a postgres:// URL that doesn't set
+// them; key=value DSNs are returned unchanged.
+func (d DatabaseConfig) withTimeouts(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return dsn
+	}
+	q := u.Query()
+	for k, v := range d.timeouts() {
+		if !q.Has(k) {
+			q[k] = v
 		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
 
-		dbURL = fmt.Sprintf(
-			"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-			user, pass, host, portDB, name,
-		)
+// Logger returns a logger writing to w at the configured level and format.
+func (l LoggingConfig) Logger(w io.Writer) *slog.Logger {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(l.Level))
+	opts := &slog.HandlerOptions{Level: level}
+	if l.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
 	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
 
-	return &Config{
-		Port:        port,
-		DatabaseURL: dbURL,
-	}, nil
+// Middleware converts c for middleware.CORS.
+func (c CORSConfig) Middleware() middleware.CORSConfig {
+	return middleware.CORSConfig{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		ExposedHeaders:   c.ExposedHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           time.Duration(c.MaxAge),
+	}
+}
+
+// Middleware converts s for middleware.SecurityHeaders.
+func (s SecurityConfig) Middleware() middleware.SecurityConfig {
+	return middleware.SecurityConfig{
+		CSP:                 s.CSP,
+		HSTSMaxAge:          time.Duration(s.HSTSMaxAge),
+		TrustForwardedProto: s.TrustForwardedProto,
+	}
+}
+
+// Duration is a time.Duration written like "720h" in files, the
+// environment and flags.
+type Duration time.Duration
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return fmt.Errorf("want a duration like 720h")
+	}
+	*d = Duration(v)
+	return nil
 }
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the config file when --config isn't given.
+const FileEnv = "DYEL_CONFIG"
+
+// Load builds the configuration in layers: Defaults, then the YAML or TOML
+// file named by --config or $DYEL_CONFIG, then environment variables, then
+// the remaining flags in args (e.g. --server.port=9090). A malformed file
+// or unknown flag fails straight away; bad values and failed checks are
+// gathered into one *ValidationError.
+func Load(args []string) (*Config, error) {
+	cfg, rest, err := LoadCommand(flag.NewFlagSet("dyel", flag.ContinueOnError), args)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("flags: unexpected argument %q", rest[0])
+	}
+	return cfg, nil
+}
+
+// LoadCommand is Load for a subcommand: fs may already hold the command's
+// own flags, which are parsed alongside the config ones, and the positional
+// arguments are returned rather than rejected. Flags and arguments may be
+// mixed; everything after "--" is positional.
+func LoadCommand(fs *flag.FlagSet, args []string) (*Config, []string, error) {
+	cfg := Defaults()
+	leaves := cfg.leaves()
+
+	fs.SetOutput(io.Discard)
+	path := fs.String("config", os.Getenv(FileEnv), "YAML or TOML config file")
+	var flagged []flagValue
+	for _, l := range leaves {
+		fs.Var(&flagRecorder{leaf: l, into: &flagged}, l.key, "env "+l.env)
+	}
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, fmt.Errorf("flags: %w", err)
+		}
+		left := fs.Args()
+		if len(left) == 0 {
+			break
+		}
+		if n := len(args) - len(left); n > 0 && args[n-1] == "--" {
+			rest = append(rest, left...)
+			break
+		}
+		rest, args = append(rest, left[0]), left[1:]
+	}
+
+	if *path != "" {
+		if err := readFile(*path, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var problems ValidationError
+	for _, l := range leaves {
+		v, ok := os.LookupEnv(l.env)
+		if l.env == "" || !ok || (v == "" && !l.allowEmpty) {
+			continue
+		}
+		if err := l.set(v); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", l.env, err))
+		}
+	}
+	for _, f := range flagged {
+		if err := f.leaf.set(f.raw); err != nil {
+			problems = append(problems, fmt.Sprintf("--%s: %v", f.leaf.key, err))
+		}
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, nil, &problems
+	}
+	return cfg, rest, nil
+}
+
+// ValidationError lists every problem found in the configuration.
+type ValidationError []string
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(*e, "\n  - ")
+}
+
+// readFile decodes a .yaml/.yml or .toml file over cfg, rejecting keys that
+// don't exist so typos don't go unnoticed.
+func readFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+		if errors.Is(err, io.EOF) {
+			err = nil // empty file
+		}
+	case ".toml":
+		err = toml.NewDecoder(bytes.NewReader(data)).DisallowUnknownFields().Decode(cfg)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q; use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// leaf is one setting: its dotted key (also its flag name), environment
+// variable and place in the Config.
+type leaf struct {
+	key        string
+	env        string
+	allowEmpty bool
+	secret     bool
+	value      reflect.Value
+}
+
+// leaves lists every setting in c in declaration order.
+func (c *Config) leaves() []leaf {
+	var out []leaf
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			key := prefix + name
+			if f.Type.Kind() == reflect.Struct {
+				walk(key+".", v.Field(i))
+				continue
+			}
+			env, opt, _ := strings.Cut(f.Tag.Get("env"), ",")
+			out = append(out, leaf{
+				key:        key,
+				env:        env,
+				allowEmpty: opt == "allowempty",
+				secret:     f.Tag.Get("secret") == "true",
+				value:      v.Field(i),
+			})
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return out
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// set parses s into the leaf. Lists are comma-separated and maps are
+// "key=value" pairs separated by semicolons.
+func (l leaf) set(s string) error {
+	v := l.value
+	switch {
+	case v.Type() == durationType:
+		var d Duration
+		if err := d.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value %q: %w", s, err)
+		}
+		v.Set(reflect.ValueOf(d))
+		return nil
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+		return nil
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid value %q: want true or false", s)
+		}
+		v.SetBool(b)
+		return nil
+	case v.CanInt():
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q: want an integer", s)
+		}
+		v.SetInt(n)
+		return nil
+	case v.CanFloat():
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q: want a number", s)
+		}
+		v.SetFloat(f)
+		return nil
+	case v.Kind() == reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+		return nil
+	case v.Kind() == reflect.Map:
+		m := map[string]string{}
+		for _, entry := range strings.Split(s, ";") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(entry, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("invalid entry %q: want name=value", entry)
+			}
+			m[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+		v.Set(reflect.ValueOf(m))
+		return nil
+	}
+	return fmt.Errorf("unsupported setting type %s", v.Type())
+}
+
+// format renders the leaf's current value the way set would accept it.
+func (l leaf) format() string {
+	v := l.value
+	switch {
+	case v.Type() == durationType:
+		return v.Interface().(Duration).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	case v.Kind() == reflect.Map:
+		m := v.Interface().(map[string]string)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, k+"="+m[k])
+		}
+		return strings.Join(parts, ";")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// flagValue is a flag seen on the command line, applied after the file and
+// the environment.
+type flagValue struct {
+	leaf leaf
+	raw  string
+}
+
+type flagRecorder struct {
+	leaf leaf
+	into *[]flagValue
+}
+
+func (r *flagRecorder) String() string {
+	if r == nil || !r.leaf.value.IsValid() {
+		return ""
+	}
+	return r.leaf.format()
+}
+
+func (r *flagRecorder) Set(s string) error {
+	*r.into = append(*r.into, flagValue{leaf: r.leaf, raw: s})
+	return nil
+}
+
+func (r *flagRecorder) IsBoolFlag() bool { return r.leaf.value.Kind() == reflect.Bool }
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "REDACTED"
+
+// Redacted returns a copy of c with every secret replaced. Database URLs
+// keep everything but their passwords.
+func (c *Config) Redacted() *Config {
+	out := *c
+	for _, l := range out.leaves() {
+		if !l.secret || l.value.IsZero() {
+			continue
+		}
+		switch l.value.Kind() {
+		case reflect.String:
+			l.value.SetString(redactString(l.key, l.value.String()))
+		case reflect.Slice:
+			masked := make([]string, l.value.Len())
+			for i := range masked {
+				masked[i] = redactString(l.key, l.value.Index(i).String())
+			}
+			l.value.Set(reflect.ValueOf(masked))
+		}
+	}
+	return &out
+}
+
+func redactString(key, s string) string {
+	if key == "database.url" || key == "database.replica_urls" {
+		if u, err := url.Parse(s); err == nil && u.Scheme != "" {
+			return u.Redacted()
+		}
+	}
+	return redacted
+}
+
+// Print writes c as YAML with secrets redacted, in the same layout a config
+// file uses.
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
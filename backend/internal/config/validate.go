@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/iraunchy/dyel/backend/internal/warmup"
+)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// minAPIKeyLen keeps guessable keys out of auth.api_keys.
+const minAPIKeyLen = 16
+
+// validate checks every setting and returns all problems found. It also
+// fills in WarmupTemplates.
+func (c *Config) validate() ValidationError {
+	var p ValidationError
+	fail := func(key, format string, args ...any) {
+		p = append(p, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	s := c.Server
+	if !validPort(s.Port) {
+		fail("server.port", "%q is not a port number", s.Port)
+	}
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		fail("server.tls_cert_file", "tls_cert_file and tls_key_file must be set together")
+	}
+	if s.TLSReloadInterval <= 0 {
+		fail("server.tls_reload_interval", "must be positive")
+	}
+	if s.HTTPRedirectPort != "" {
+		switch {
+		case !validPort(s.HTTPRedirectPort):
+			fail("server.http_redirect_port", "%q is not a port number", s.HTTPRedirectPort)
+		case s.TLSCertFile == "":
+			fail("server.http_redirect_port", "needs tls_cert_file and tls_key_file")
+		case s.HTTPRedirectPort == s.Port:
+			fail("server.http_redirect_port", "must differ from server.port")
+		}
+	}
+	if s.MaxBodyBytes < 1 {
+		fail("server.max_body_bytes", "must be positive")
+	}
+	for _, p := range s.TrustedProxies {
+		_, prefixErr := netip.ParsePrefix(p)
+		if _, addrErr := netip.ParseAddr(p); prefixErr != nil && addrErr != nil {
+			fail("server.trusted_proxies", "%q is not an IP address or CIDR range", p)
+		}
+	}
+	if s.PublicURL != "" {
+		if u, err := url.Parse(s.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("server.public_url", "%q is not an http or https URL", s.PublicURL)
+		}
+	}
+
+	d := c.Database
+	if d.URL == "" && (d.User == "" || d.Password == "" || d.Name == "") {
+		fail("database", "either url or all of user, password and name must be set")
+	}
+	if d.URL == "" && !validPort(d.Port) {
+		fail("database.port", "%q is not a port number", d.Port)
+	}
+	if !oneOf(d.SSLMode, sslModes...) {
+		fail("database.ssl_mode", "%q is not one of %v", d.SSLMode, sslModes)
+	}
+	if d.MaxOpenConns < 0 {
+		fail("database.max_open_conns", "must not be negative")
+	}
+	if d.MaxIdleConns < 0 {
+		fail("database.max_idle_conns", "must not be negative")
+	} else if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		fail("database.max_idle_conns", "must not exceed max_open_conns (%d)", d.MaxOpenConns)
+	}
+	for key, v := range map[string]Duration{
+		"database.conn_max_lifetime":  d.ConnMaxLifetime,
+		"database.conn_max_idle_time": d.ConnMaxIdleTime,
+		"database.connect_timeout":    d.ConnectTimeout,
+		"database.statement_timeout":  d.StatementTimeout,
+	} {
+		if v < 0 {
+			fail(key, "must not be negative")
+		}
+	}
+
+	if len(d.ReplicaURLs) > 0 && d.ReplicaCheckInterval <= 0 {
+		fail("database.replica_check_interval", "must be positive")
+	}
+
+	if !oneOf(c.Logging.Level, "debug", "info", "warn", "error") {
+		fail("logging.level", "%q is not one of debug, info, warn, error", c.Logging.Level)
+	}
+	if !oneOf(c.Logging.Format, "text", "json") {
+		fail("logging.format", "%q is not one of text, json", c.Logging.Format)
+	}
+
+	for i, k := range c.Auth.APIKeys {
+		if len(k) < minAPIKeyLen {
+			fail("auth.api_keys", "key %d is shorter than %d characters", i+1, minAPIKeyLen)
+		}
+	}
+
+	pr := c.Programs
+	for key, v := range map[string]int{
+		"programs.batch_max_items": pr.BatchMaxItems,
+		"programs.max_days":        pr.MaxDays,
+		"programs.max_exercises":   pr.MaxExercises,
+	} {
+		if v < 1 {
+			fail(key, "must be positive")
+		}
+	}
+	if pr.TrashRetention <= 0 {
+		fail("programs.trash_retention", "must be positive")
+	}
+	if pr.TrashPurgeInterval <= 0 {
+		fail("programs.trash_purge_interval", "must be positive")
+	}
+	c.WarmupTemplates = make(map[string]warmup.Template, len(warmup.Defaults))
+	for name, t := range warmup.Defaults {
+		c.WarmupTemplates[name] = t
+	}
+	names := make([]string, 0, len(pr.WarmupTemplates))
+	for name := range pr.WarmupTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t, err := warmup.Parse(name, pr.WarmupTemplates[name])
+		if err != nil {
+			fail("programs.warmup_templates", "%v", err)
+			continue
+		}
+		c.WarmupTemplates[name] = t
+	}
+
+	rl := c.RateLimit
+	if rl.IP < 0 {
+		fail("rate_limit.ip_rps", "must not be negative")
+	}
+	if rl.User < 0 {
+		fail("rate_limit.user_rps", "must not be negative")
+	}
+	if rl.IPBurst < 1 {
+		fail("rate_limit.ip_burst", "must be positive")
+	}
+	if rl.UserBurst < 1 {
+		fail("rate_limit.user_burst", "must be positive")
+	}
+
+	if c.CORS.MaxAge < 0 {
+		fail("cors.max_age", "must not be negative")
+	}
+	if c.CORS.AllowCredentials && oneOf("*", c.CORS.AllowedOrigins...) {
+		fail("cors.allow_credentials", `browsers reject credentials with the "*" origin; list the origins instead`)
+	}
+	if c.Security.HSTSMaxAge < 0 {
+		fail("security.hsts_max_age", "must not be negative")
+	}
+
+	if c.Cache.Size < 0 {
+		fail("cache.programs_size", "must not be negative")
+	}
+	if c.Cache.TTL <= 0 {
+		fail("cache.programs_ttl", "must be positive")
+	}
+
+	sort.Strings(p) // map iteration above is unordered
+	return p
+}
+
+func validPort(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n < 1<<16
+}
+
+func oneOf(s string, allowed ...string) bool {
+	for _, a := range allowed {
+		if s == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/iraunchy/dyel/backend/db"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+)
+
+// BatchCreatePrograms handles POST /api/v1/programs/batch. The body is an
+// array of CreateProgramInput. Every item is validated first; the response
+// is 201 when all were created, 207 when best-effort left some behind and
+// 422 when an atomic batch was rejected.
+func (h *Handler) BatchCreatePrograms(c *gin.Context) {
+	q, err := BindQuery[BatchQuery](c)
+	if err != nil {
+		httpresp.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	if q.Mode == "" {
+		q.Mode = BatchAtomic
+	}
+
+	raw, err := BindJSON[[]json.RawMessage](c)
+	if err != nil {
+		httpresp.Error(c, statusFor(err, http.StatusBadRequest), err)
+		return
+	}
+	limit := h.BatchLimit
+	if limit == 0 {
+		limit = defaultBatchLimit
+	}
+	switch {
+	case len(raw) == 0:
+		httpresp.Error(c, http.StatusBadRequest, fmt.Errorf("batch is empty"))
+		return
+	case len(raw) > limit:
+		httpresp.Error(c, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("batch has %d items; the limit is %d", len(raw), limit))
+		return
+	}
+
+	res := BatchResult{Mode: q.Mode, Items: make([]BatchItemResult, len(raw))}
+	programs := make([]*db.Program, len(raw))
+	invalid := 0
+	for i, item := range raw {
+		res.Items[i].Index = i
+		var in CreateProgramInput
+		err := json.Unmarshal(item, &in)
+		if err == nil {
+			err = binding.Validator.ValidateStruct(&in)
+		}
+		if err == nil {
+			err = h.checkSize(in.Days)
+		}
+		if err != nil {
+			res.Items[i].Status = BatchInvalid
+			res.Items[i].Error = err.Error()
+			invalid++
+			continue
+		}
+		programs[i] = in.ToModel()
+	}
+
+	ctx := c.Request.Context()
+	if q.Mode == BatchAtomic {
+		if invalid == 0 {
+			if _, err := h.Repo.CreateMany(ctx, programs); err != nil {
+				for i := range res.Items {
+					res.Items[i].Status = BatchFailed
+					res.Items[i].Error = err.Error()
+				}
+				res.Failed = len(raw)
+				httpresp.JSON(c, http.StatusInternalServerError, res)
+				return
+			}
+		}
+		for i, p := range programs {
+			switch {
+			case invalid > 0 && p != nil:
+				res.Items[i].Status = BatchSkipped
+			case p != nil:
+				res.Items[i].Status = BatchCreated
+				res.Items[i].ID = p.ID
+				res.Created++
+			}
+		}
+		if invalid > 0 {
+			res.Failed = invalid
+			httpresp.JSON(c, http.StatusUnprocessableEntity, res)
+			return
+		}
+		httpresp.Created(c, res)
+		return
+	}
+
+	for i, p := range programs {
+		if p == nil {
+			continue
+		}
+		if _, err := h.Repo.Create(ctx, p); err != nil {
+			res.Items[i].Status = BatchFailed
+			res.Items[i].Error = err.Error()
+			continue
+		}
+		res.Items[i].Status = BatchCreated
+		res.Items[i].ID = p.ID
+		res.Created++
+	}
+	res.Failed = len(raw) - res.Created
+	if res.Failed > 0 {
+		httpresp.JSON(c, http.StatusMultiStatus, res)
+		return
+	}
+	httpresp.Created(c, res)
+}
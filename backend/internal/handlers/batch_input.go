@@ -0,0 +1,43 @@
+package handlers
+
+// Modes for POST /programs/batch.
+const (
+	// BatchAtomic inserts every item in one transaction, or none if any
+	// item is invalid or fails.
+	BatchAtomic = "atomic"
+	// BatchBestEffort inserts each valid item on its own and reports the
+	// rest.
+	BatchBestEffort = "best_effort"
+)
+
+// Per-item outcomes reported by POST /programs/batch.
+const (
+	BatchCreated = "created"
+	BatchInvalid = "invalid"
+	BatchFailed  = "failed"
+	BatchSkipped = "skipped"
+)
+
+// defaultBatchLimit caps the items in one batch unless configured.
+const defaultBatchLimit = 100
+
+// BatchQuery maps the query string for POST /programs/batch.
+type BatchQuery struct {
+	Mode string `form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+}
+
+// BatchItemResult reports what happened to one element of the request array.
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchResult is the POST /programs/batch response.
+type BatchResult struct {
+	Mode    string            `json:"mode"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Items   []BatchItemResult `json:"items"`
+}
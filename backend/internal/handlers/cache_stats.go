@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/internal/cache"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+)
+
+// CacheStatsResponse reports each cache by name.
+type CacheStatsResponse struct {
+	Programs cache.Stats `json:"programs"`
+}
+
+// GetCacheStats handles GET /api/v1/cache/stats
+func (h *Handler) GetCacheStats(c *gin.Context) {
+	httpresp.JSON(c, http.StatusOK, CacheStatsResponse{Programs: h.CacheStats()})
+}
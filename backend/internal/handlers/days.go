@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/loads"
+	"github.com/iraunchy/dyel/backend/internal/plates"
+	"github.com/iraunchy/dyel/backend/internal/session"
+)
+
+// DaySession handles GET /api/v1/programs/:id/days/:dayId/session
+func (h *Handler) DaySession(c *gin.Context) {
+	HandleJSON[SessionInput, session.Day](
+		c,
+		func(c *gin.Context) (SessionInput, error) {
+			uri, err := BindURI[DayURI](c)
+			if err != nil {
+				return SessionInput{}, err
+			}
+			q, err := BindQuery[SessionQuery](c)
+			return SessionInput{DayURI: uri, SessionQuery: q}, err
+		},
+		func(ctx context.Context, in SessionInput) (session.Day, error) {
+			inv, err := h.inventory(ctx, in.InventoryID, in.Unit)
+			if err != nil {
+				return session.Day{}, err
+			}
+			d, err := h.loadDay(ctx, in.DayURI, in.UserID)
+			if err != nil {
+				return session.Day{}, err
+			}
+			return session.Build(d, inv), nil
+		},
+		http.StatusOK,
+	)
+}
+
+// loadDay fetches one day of a program, resolving percentage loads against
+// userID's training maxes when given.
+func (h *Handler) loadDay(ctx context.Context, uri DayURI, userID string) (db.Day, error) {
+	p, err := h.Repo.Get(ctx, uri.ID)
+	if err != nil {
+		return db.Day{}, err
+	}
+	if userID != "" && h.TrainingMaxes != nil {
+		maxes, err := h.TrainingMaxes.List(ctx, userID)
+		if err != nil {
+			return db.Day{}, err
+		}
+		loads.Resolve(p, maxes)
+	}
+
+	for _, d := range p.Days {
+		if d.ID == uri.DayID {
+			return d, nil
+		}
+	}
+	return db.Day{}, &StatusError{http.StatusNotFound, fmt.Errorf("day %s not found in program %s", uri.DayID, uri.ID)}
+}
+
+// inventory returns the stored inventory id, or the standard one for unit
+// (kg when empty) if id is empty.
+func (h *Handler) inventory(ctx context.Context, id, unit string) (db.PlateInventory, error) {
+	if id == "" {
+		if unit == "" {
+			unit = db.UnitKg
+		}
+		return plates.Defaults[unit], nil
+	}
+	if h.Plates == nil {
+		return db.PlateInventory{}, &StatusError{http.StatusBadRequest, fmt.Errorf("plate inventories are not enabled")}
+	}
+	inv, err := h.Plates.Get(ctx, id)
+	if err != nil {
+		return db.PlateInventory{}, err
+	}
+	return *inv, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// ErrPreconditionRequired is returned for writes without If-Match when the
+// handler is configured to require one.
+var ErrPreconditionRequired = &StatusError{
+	Code: http.StatusPreconditionRequired,
+	Err:  errors.New("If-Match header is required"),
+}
+
+// IfMatchHeader binds the precondition header of PUT, PATCH and DELETE.
+type IfMatchHeader struct {
+	IfMatch string `header:"If-Match"`
+}
+
+// IfNoneMatchHeader binds the cache validator header of GET.
+type IfNoneMatchHeader struct {
+	IfNoneMatch string `header:"If-None-Match"`
+}
+
+// programETag renders the strong entity tag for a program version.
+func programETag(p *db.Program) string {
+	return `"v` + strconv.Itoa(p.Version) + `"`
+}
+
+// versionFromIfMatch returns the version a write must match: 0 when there is
+// no If-Match (or it is "*"), -1 when the tag isn't one of ours so that the
+// write fails the precondition.
+func (h *Handler) versionFromIfMatch(c *gin.Context) (int, error) {
+	hdr, err := BindHeader[IfMatchHeader](c)
+	if err != nil {
+		return 0, err
+	}
+
+	ifMatch := strings.TrimSpace(hdr.IfMatch)
+	switch ifMatch {
+	case "":
+		if h.RequireIfMatch {
+			return 0, ErrPreconditionRequired
+		}
+		return 0, nil
+	case "*":
+		return 0, nil
+	}
+
+	// only a single strong tag can identify one version
+	tag := strings.TrimSuffix(strings.TrimPrefix(ifMatch, `"v`), `"`)
+	v, err := strconv.Atoi(tag)
+	if err != nil || strings.Contains(ifMatch, ",") || v <= 0 {
+		return -1, nil
+	}
+	return v, nil
+}
+
+// etagMatches implements the weak comparison used by If-None-Match.
+func etagMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// setETag adds the program's ETag to the response.
+func setETag(c *gin.Context, p *db.Program) {
+	if p != nil {
+		c.Header("ETag", programETag(p))
+	}
+}
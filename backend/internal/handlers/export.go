@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+)
+
+// exportBatchSize is how many programs are read, and written before a
+// flush, at a time.
+const exportBatchSize = 100
+
+// exportBatchTimeout is how long writing each batch may take. The server's
+// write timeout covers a whole response, so the export moves the deadline
+// on before every batch instead.
+const exportBatchTimeout = time.Minute
+
+// exportCountTrailer is sent after the last line with the number of
+// programs written. A dump that lacks it was cut short.
+const exportCountTrailer = "X-Export-Count"
+
+// ExportPrograms handles GET /api/v1/programs/export.ndjson. Programs are
+// streamed as they are read, so memory use doesn't grow with the library,
+// and the export stops as soon as the client disconnects.
+func (h *Handler) ExportPrograms(c *gin.Context) {
+	ctx := c.Request.Context()
+	enc := json.NewEncoder(c.Writer)
+	rc := http.NewResponseController(c.Writer)
+	written := 0
+	start := func() {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="programs.ndjson"`)
+		c.Header("Trailer", exportCountTrailer)
+		c.Status(http.StatusOK)
+	}
+
+	err := h.Export.Each(ctx, exportBatchSize, func(p *db.Program) error {
+		if written == 0 {
+			start()
+		}
+		if written%exportBatchSize == 0 {
+			// not every writer has deadlines; the recorder in tests doesn't
+			_ = rc.SetWriteDeadline(time.Now().Add(exportBatchTimeout))
+		}
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+		written++
+		if written%exportBatchSize == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	switch {
+	case err != nil && written == 0:
+		httpresp.Error(c, statusFor(err, http.StatusInternalServerError), err)
+		return
+	case err != nil:
+		// Too late for an error status; the missing trailer tells the
+		// client the dump is incomplete.
+		if ctx.Err() == nil {
+			slog.Error("export: stopped early", "written", written, "err", err)
+		}
+		return
+	case written == 0:
+		start()
+	}
+	c.Writer.Header().Set(exportCountTrailer, strconv.Itoa(written))
+	c.Writer.Flush()
+}
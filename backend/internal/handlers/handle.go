@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+	"gorm.io/gorm"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -24,13 +27,13 @@ func HandleJSON[T any, R any](
 ) {
 	in, err := binder(c)
 	if err != nil {
-		httpresp.Error(c, http.StatusBadRequest, err)
+		httpresp.Error(c, statusFor(err, http.StatusBadRequest), err)
 		return
 	}
 
 	out, err := action(c.Request.Context(), in)
 	if err != nil {
-		httpresp.Error(c, http.StatusInternalServerError, err)
+		httpresp.Error(c, statusFor(err, http.StatusInternalServerError), err)
 		return
 	}
 
@@ -41,6 +44,34 @@ func HandleJSON[T any, R any](
 	}
 }
 
+// StatusError attaches an HTTP status to an error returned by a binder or
+// action.
+type StatusError struct {
+	Code int
+	Err  error
+}
+
+func (e *StatusError) Error() string { return e.Err.Error() }
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// statusFor picks the response status for err, falling back to fallback for
+// errors it doesn't recognise.
+func statusFor(err error, fallback int) int {
+	var se *StatusError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &se):
+		return se.Code
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, repos.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+	return fallback
+}
+
 func BindURI[T any](c *gin.Context) (T, error) {
 	var in T
 	return in, c.ShouldBindUri(&in)
@@ -49,3 +80,11 @@ func BindJSON[T any](c *gin.Context) (T, error) {
 	var in T
 	return in, c.ShouldBindJSON(&in)
 }
+func BindQuery[T any](c *gin.Context) (T, error) {
+	var in T
+	return in, c.ShouldBindQuery(&in)
+}
+func BindHeader[T any](c *gin.Context) (T, error) {
+	var in T
+	return in, c.ShouldBindHeader(&in)
+}
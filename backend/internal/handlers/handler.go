@@ -1,14 +1,136 @@
 package handlers
 
 import (
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/internal/cache"
 	"github.com/iraunchy/dyel/backend/internal/repos"
+	"github.com/iraunchy/dyel/backend/internal/templates"
+	"github.com/iraunchy/dyel/backend/internal/warmup"
 )
 
 type Handler struct {
-	Repo repos.ProgramRepo
+	Repo     repos.ProgramRepo
+	Webhooks repos.WebhookRepo
+	// PrivateWebhooks lets webhooks be registered on loopback and private
+	// addresses, for receivers on the same network.
+	PrivateWebhooks bool
+	Search          repos.SearchRepo
+	Trash           repos.TrashRepo
+	// TrainingMaxes resolves percentage loads on GET /programs/:id?user_id=.
+	TrainingMaxes repos.TrainingMaxRepo
+	// Plates stores plate inventories for the calculator and session view.
+	Plates repos.PlateInventoryRepo
+	// RequireIfMatch rejects PUT, PATCH and DELETE without If-Match (428).
+	RequireIfMatch bool
+	// BatchLimit caps the items in POST /programs/batch.
+	BatchLimit int
+	// MaxDays and MaxExercises cap the size of a program written through
+	// the API; zero means no cap.
+	MaxDays      int
+	MaxExercises int
+	// Middleware runs in front of every /api/v1 route.
+	Middleware []gin.HandlerFunc
+	// WarmupTemplates are the ramps offered by the warm-up endpoint;
+	// warmup.Defaults when nil.
+	WarmupTemplates map[string]warmup.Template
+	// Export streams every program for GET /programs/export.ndjson.
+	Export repos.ProgramExporter
+	// Templates is the program template library behind /templates.
+	Templates *templates.Library
+	// CacheStats reports the program cache's hits and misses.
+	CacheStats func() cache.Stats
+	// PublicURL is the web app's address, for links such as the QR code on
+	// printed sheets; sheets have no link when it is empty.
+	PublicURL string
+}
+
+// Option configures optional Handler dependencies.
+type Option func(*Handler)
+
+// WithWebhooks enables the /webhooks endpoints backed by the given repo.
+func WithWebhooks(w repos.WebhookRepo) Option {
+	return func(h *Handler) { h.Webhooks = w }
+}
+
+// WithPrivateWebhooks allows webhook endpoints on private addresses.
+func WithPrivateWebhooks(allow bool) Option {
+	return func(h *Handler) { h.PrivateWebhooks = allow }
+}
+
+// WithSearch enables GET /search backed by the given repo.
+func WithSearch(s repos.SearchRepo) Option {
+	return func(h *Handler) { h.Search = s }
+}
+
+// WithTrash enables the trash listing and restore endpoints.
+func WithTrash(t repos.TrashRepo) Option {
+	return func(h *Handler) { h.Trash = t }
+}
+
+// WithTrainingMaxes enables the /training-maxes endpoints and resolving
+// percentage prescriptions against them.
+func WithTrainingMaxes(t repos.TrainingMaxRepo) Option {
+	return func(h *Handler) { h.TrainingMaxes = t }
+}
+
+// WithPlateInventories enables the /plate-inventories endpoints and using a
+// stored inventory in the plate calculator and session view.
+func WithPlateInventories(p repos.PlateInventoryRepo) Option {
+	return func(h *Handler) { h.Plates = p }
+}
+
+// WithRequireIfMatch makes If-Match mandatory on program writes.
+func WithRequireIfMatch(require bool) Option {
+	return func(h *Handler) { h.RequireIfMatch = require }
+}
+
+// WithBatchLimit caps the number of programs in one batch request.
+func WithBatchLimit(n int) Option {
+	return func(h *Handler) { h.BatchLimit = n }
+}
+
+// WithProgramLimits caps the days, and the exercises across all days, of a
+// program created or updated through the API.
+func WithProgramLimits(maxDays, maxExercises int) Option {
+	return func(h *Handler) { h.MaxDays, h.MaxExercises = maxDays, maxExercises }
+}
+
+// WithMiddleware adds middleware, such as rate limiting, to the /api/v1
+// group.
+func WithMiddleware(mw ...gin.HandlerFunc) Option {
+	return func(h *Handler) { h.Middleware = append(h.Middleware, mw...) }
+}
+
+// WithWarmupTemplates replaces the built-in warm-up templates.
+func WithWarmupTemplates(t map[string]warmup.Template) Option {
+	return func(h *Handler) { h.WarmupTemplates = t }
+}
+
+// WithExport enables GET /programs/export.ndjson backed by e.
+func WithExport(e repos.ProgramExporter) Option {
+	return func(h *Handler) { h.Export = e }
+}
+
+// WithTemplates enables GET /templates and creating programs from them.
+func WithTemplates(lib *templates.Library) Option {
+	return func(h *Handler) { h.Templates = lib }
+}
+
+// WithCacheStats enables GET /cache/stats, reporting stats.
+func WithCacheStats(stats func() cache.Stats) Option {
+	return func(h *Handler) { h.CacheStats = stats }
+}
+
+// WithPublicURL sets the address links back to the web app start with.
+func WithPublicURL(u string) Option {
+	return func(h *Handler) { h.PublicURL = u }
 }
 
 // NewHandler wires in a ProgramRepo
-func NewHandler(r repos.ProgramRepo) *Handler {
-	return &Handler{Repo: r}
+func NewHandler(r repos.ProgramRepo, opts ...Option) *Handler {
+	h := &Handler{Repo: r}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
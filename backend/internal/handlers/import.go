@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+	"github.com/iraunchy/dyel/backend/internal/importers"
+)
+
+// ImportProgram handles POST /api/v1/programs/import. The body is a CSV
+// file exported from Strong or Hevy, or laid out as
+// importers.GenericColumns. The response shows the program made of it and
+// the exercise names that were kept as written: 200 for a dry run, 201 once
+// the program is created, and 422 when the result isn't a valid program.
+func (h *Handler) ImportProgram(c *gin.Context) {
+	q, err := BindQuery[ImportQuery](c)
+	if err != nil {
+		httpresp.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := importers.Parse(q.Format, c.Request.Body, importers.Options{
+		Name:     q.Name,
+		SharedBy: q.SharedBy,
+		Unit:     q.Unit,
+		Window:   time.Duration(q.Weeks) * 7 * 24 * time.Hour,
+	})
+	if err != nil {
+		httpresp.Error(c, statusFor(err, http.StatusBadRequest), err)
+		return
+	}
+
+	in := CreateProgramInput{Name: res.Program.Name, SharedBy: res.Program.SharedBy, Days: res.Program.Days}
+	if err := binding.Validator.ValidateStruct(&in); err != nil {
+		httpresp.Error(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err := h.checkSize(in.Days); err != nil {
+		httpresp.Error(c, statusFor(err, http.StatusBadRequest), err)
+		return
+	}
+	if q.DryRun {
+		httpresp.JSON(c, http.StatusOK, res)
+		return
+	}
+
+	if res.Program, err = h.Repo.Create(c.Request.Context(), res.Program); err != nil {
+		httpresp.Error(c, statusFor(err, http.StatusInternalServerError), err)
+		return
+	}
+	httpresp.Created(c, res)
+}
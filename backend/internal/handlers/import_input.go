@@ -0,0 +1,14 @@
+package handlers
+
+// ImportQuery maps the query string for POST /programs/import; the body is
+// the file itself. Weeks is how far back from its latest workout a history
+// counts as the current routine. DryRun parses the file and reports what
+// would be created without creating it.
+type ImportQuery struct {
+	Format   string `form:"format" binding:"required,oneof=strong hevy generic"`
+	SharedBy string `form:"shared_by" binding:"required"`
+	Name     string `form:"name"`
+	Unit     string `form:"unit" binding:"omitempty,oneof=kg lb"`
+	Weeks    int    `form:"weeks" binding:"omitempty,min=1,max=520"`
+	DryRun   bool   `form:"dry_run"`
+}
@@ -2,37 +2,96 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/cache"
+	"github.com/iraunchy/dyel/backend/internal/importers"
+	"github.com/iraunchy/dyel/backend/internal/middleware"
+	"github.com/iraunchy/dyel/backend/internal/plates"
 	"github.com/iraunchy/dyel/backend/internal/repos"
+	"github.com/iraunchy/dyel/backend/internal/session"
+	"github.com/iraunchy/dyel/backend/internal/templates"
+	"github.com/iraunchy/dyel/backend/internal/warmup"
 )
 
-func setupRouter(t *testing.T) *gin.Engine {
-	dbConn, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	assert.NoError(t, err)
-
-	assert.NoError(t, dbConn.AutoMigrate(&db.Program{}, &db.Day{}, &db.Exercise{}))
+// setupDB opens an in-memory database private to the test and migrates it.
+func setupDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dbConn, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbConn))
+	return dbConn
+}
 
+// setupRouter serves the API over a fresh database with search, the trash
+// and opts enabled, for tests that only need HTTP.
+func setupRouter(t *testing.T, opts ...Option) *gin.Engine {
+	dbConn := setupDB(t)
 	repo := repos.NewGORMProgramRepo(dbConn)
+	return newRouter(repo, append([]Option{
+		WithSearch(repos.NewGORMSearchRepo(dbConn)),
+		WithTrash(repo),
+	}, opts...)...)
+}
 
-	h := NewHandler(repo)
-
+// newRouter serves a Handler for repo configured with opts.
+func newRouter(repo repos.ProgramRepo, opts ...Option) *gin.Engine {
 	r := gin.New()
 	r.Use(gin.Recovery())
-	h.RegisterRoutes(r)
-
+	NewHandler(repo, opts...).RegisterRoutes(r)
 	return r
 }
 
+// do sends a request through router. A string body is sent as it is and
+// any other non-nil body as JSON. Headers come in name, value pairs;
+// Content-Type defaults to application/json.
+func do(router http.Handler, method, url string, body any, headers ...string) *httptest.ResponseRecorder {
+	var rdr io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		rdr = strings.NewReader(b)
+	default:
+		buf, err := json.Marshal(b)
+		if err != nil {
+			panic(err)
+		}
+		rdr = bytes.NewReader(buf)
+	}
+	req := httptest.NewRequest(method, url, rdr)
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals a JSON response.
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &v), w.Body.String())
+	return v
+}
+
 func TestCreateAndFetchProgram(t *testing.T) {
 	router := setupRouter(t)
 
@@ -86,3 +145,957 @@ func TestCreateAndFetchProgram(t *testing.T) {
 		assert.NotEmpty(t, d.CreatedAt)
 	}
 }
+
+func TestConditionalRequests(t *testing.T) {
+	router := setupRouter(t)
+
+	w := do(router, "POST", "/api/v1/programs", map[string]any{
+		"name": "5x5", "shared_by": "coach@example.com", "days": []any{},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := decode[db.Program](t, w)
+	assert.Equal(t, 1, created.Version)
+	url := "/api/v1/programs/" + created.ID
+
+	w = do(router, "GET", url, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"v1"`, etag)
+
+	w = do(router, "GET", url, nil, "If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	update := map[string]any{"name": "5x5 v2", "shared_by": "coach@example.com"}
+	w = do(router, "PUT", url, update, "If-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"v2"`, w.Header().Get("ETag"))
+
+	// the second coach still holds v1
+	w = do(router, "PUT", url, update, "If-Match", etag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = do(router, "PATCH", url, map[string]any{"name": "mine"}, "If-Match", etag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = do(router, "DELETE", url, nil, "If-Match", etag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = do(router, "PATCH", url, map[string]any{"name": "5x5 v3"}, "If-Match", `"v2"`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	patched := decode[db.Program](t, w)
+	assert.Equal(t, "5x5 v3", patched.Name)
+	assert.Equal(t, "coach@example.com", patched.SharedBy)
+	assert.Equal(t, 3, patched.Version)
+
+	w = do(router, "DELETE", url, nil, "If-Match", `"v3"`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = do(router, "GET", url, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSearchPrograms(t *testing.T) {
+	router := setupRouter(t)
+
+	create := func(payload map[string]any) db.Program {
+		w := do(router, "POST", "/api/v1/programs", payload)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		return decode[db.Program](t, w)
+	}
+	search := func(q string) []repos.SearchHit {
+		w := do(router, "GET", "/api/v1/search?q="+url.QueryEscape(q), nil)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return decode[[]repos.SearchHit](t, w)
+	}
+
+	zephyr := create(map[string]any{
+		"name": "Zephyr Push Pull", "shared_by": "searcher@example.com",
+		"days": []map[string]any{{
+			"name":      "Zephyr Push",
+			"exercises": []map[string]any{{"name": "Quasar Bench Press", "sets": 4, "reps": "8", "rest": "90s"}},
+		}},
+	})
+	other := create(map[string]any{
+		"name": "Upper <b>Lower</b>", "shared_by": "other@example.com",
+		"days": []map[string]any{{
+			"name":      "Upper",
+			"exercises": []map[string]any{{"name": "Quasar Overhead Press", "sets": 3, "reps": "5", "rest": "2m"}},
+		}},
+	})
+
+	hits := search("quasar bench")
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, zephyr.ID, hits[0].Program.ID)
+		assert.Len(t, hits[0].Program.Days, 1)
+		assert.Contains(t, hits[0].Highlights, repos.Highlight{
+			Field:    db.SearchFieldExerciseName,
+			EntityID: zephyr.Days[0].Exercises[0].ID,
+			Snippet:  "<mark>Quasar</mark> <mark>Bench</mark> Press",
+		})
+	}
+
+	// program name and day name both match, so zephyr outranks other
+	hits = search("zephyr")
+	if assert.Len(t, hits, 1) {
+		assert.Len(t, hits[0].Highlights, 2)
+	}
+	hits = search("quasar")
+	assert.Len(t, hits, 2)
+
+	hits = search("lower")
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, other.ID, hits[0].Program.ID)
+		assert.Equal(t, "Upper &lt;b&gt;<mark>Lower</mark>&lt;/b&gt;", hits[0].Highlights[0].Snippet)
+	}
+
+	hits = search("searcher@example.com")
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, db.SearchFieldSharedBy, hits[0].Highlights[0].Field)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(router, "GET", "/api/v1/search", nil).Code)
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	dbConn := setupDB(t)
+	repo := repos.NewGORMProgramRepo(dbConn)
+	router := newRouter(repo, WithTrash(repo), WithSearch(repos.NewGORMSearchRepo(dbConn)))
+
+	send := func(method, url string) *httptest.ResponseRecorder {
+		return do(router, method, url, nil)
+	}
+	trashed := func() []db.Program {
+		w := send("GET", "/api/v1/programs/trash")
+		assert.Equal(t, http.StatusOK, w.Code)
+		return decodeList(t, w)
+	}
+
+	p, err := repo.Create(context.Background(), &db.Program{
+		Name: "Oops Program", SharedBy: "coach@example.com",
+		Days: []db.Day{{Name: "Monday", Exercises: []db.Exercise{{Name: "Squat"}}}},
+	})
+	assert.NoError(t, err)
+	url := "/api/v1/programs/" + p.ID
+
+	assert.Equal(t, http.StatusNoContent, send("DELETE", url).Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", url).Code)
+	assert.Contains(t, send("GET", "/api/v1/search?q=oops").Body.String(), "[]")
+
+	list := trashed()
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, p.ID, list[0].ID)
+		assert.True(t, list[0].DeletedAt.Valid)
+		assert.Len(t, list[0].Days, 1)
+		assert.Len(t, list[0].Days[0].Exercises, 1)
+	}
+
+	w := send("POST", url+"/restore")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"v2"`, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, send("GET", url).Code)
+	assert.Empty(t, trashed())
+	assert.Equal(t, http.StatusNotFound, send("POST", url+"/restore").Code)
+
+	// purge only removes what has expired
+	assert.Equal(t, http.StatusNoContent, send("DELETE", url).Code)
+	n, err := repo.Purge(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = repo.Purge(context.Background(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	assert.Empty(t, trashed())
+
+	var days, exercises int64
+	dbConn.Model(&db.Day{}).Where("program_id = ?", p.ID).Count(&days)
+	dbConn.Model(&db.Exercise{}).Where("day_id = ?", p.Days[0].ID).Count(&exercises)
+	assert.Zero(t, days)
+	assert.Zero(t, exercises)
+}
+
+func TestBatchCreatePrograms(t *testing.T) {
+	dbConn := setupDB(t)
+	router := newRouter(repos.NewGORMProgramRepo(dbConn), WithBatchLimit(3))
+
+	post := func(url string, items ...map[string]any) (int, BatchResult) {
+		w := do(router, "POST", url, items)
+		var res BatchResult
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		return w.Code, res
+	}
+	count := func() int64 {
+		var n int64
+		dbConn.Model(&db.Program{}).Count(&n)
+		return n
+	}
+	good := func(name string) map[string]any {
+		return map[string]any{"name": name, "shared_by": "gym@example.com", "days": []any{}}
+	}
+	bad := map[string]any{"name": "no author", "days": []any{}}
+
+	code, res := post("/api/v1/programs/batch", good("A"), good("B"))
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, BatchAtomic, res.Mode)
+	assert.Equal(t, 2, res.Created)
+	assert.NotEmpty(t, res.Items[1].ID)
+	assert.EqualValues(t, 2, count())
+
+	code, _ = post("/api/v1/programs:other", good("X"))
+	assert.Equal(t, http.StatusNotFound, code, "only the literal route")
+
+	code, res = post("/api/v1/programs/batch", good("C"), bad)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, BatchSkipped, res.Items[0].Status)
+	assert.Equal(t, BatchInvalid, res.Items[1].Status)
+	assert.Contains(t, res.Items[1].Error, "SharedBy")
+	assert.EqualValues(t, 2, count(), "atomic batch must not insert anything")
+
+	code, res = post("/api/v1/programs/batch?mode=best_effort", good("C"), bad)
+	assert.Equal(t, http.StatusMultiStatus, code)
+	assert.Equal(t, BatchCreated, res.Items[0].Status)
+	assert.Equal(t, BatchInvalid, res.Items[1].Status)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 1, res.Failed)
+	assert.EqualValues(t, 3, count())
+
+	code, _ = post("/api/v1/programs/batch", good("1"), good("2"), good("3"), good("4"))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	code, _ = post("/api/v1/programs:bulk", good("X"))
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = post("/api/v1/programs/batch?mode=yolo", good("X"))
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestExerciseGroups(t *testing.T) {
+	router := setupRouter(t)
+
+	post := func(url, body string) *httptest.ResponseRecorder {
+		return do(router, "POST", url, body)
+	}
+
+	w := post("/api/v1/programs", `{"name":"Bad","shared_by":"a@example.com","days":[
+		{"name":"Push","exercises":[{"name":"Bench","sets":3,"group":"A"}]}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "unknown group label")
+
+	w = post("/api/v1/programs", `{"name":"Bad","shared_by":"a@example.com","days":[
+		{"name":"Push","groups":[{"label":"A","type":"tabata"}]}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "unknown group type")
+
+	w = post("/api/v1/programs", `{"name":"Hybrid","shared_by":"a@example.com","days":[
+		{"name":"Push",
+		 "groups":[
+			{"label":"A","type":"superset"},
+			{"label":"B","type":"circuit","rounds":3,"rest_between_rounds":"60s"}],
+		 "exercises":[
+			{"name":"Overhead Press","sets":5,"reps":"5"},
+			{"name":"Bench","sets":4,"reps":"8","group":"A"},
+			{"name":"Row","sets":4,"reps":"8","group":"A"},
+			{"name":"Burpees","reps":"10","group":"B"},
+			{"name":"Push-ups","reps":"15","group":"B"}]}]}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	created := decode[db.Program](t, w)
+
+	w = post("/api/v1/programs/"+created.ID+"/fork", `{"shared_by":"b@example.com"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	fork := decode[db.Program](t, w)
+
+	for _, id := range []string{created.ID, fork.ID} {
+		p := decode[db.Program](t, do(router, "GET", "/api/v1/programs/"+id, nil))
+		if assert.Len(t, p.Days, 1) && assert.Len(t, p.Days[0].Groups, 2) {
+			circuit := p.Days[0].GroupFor(db.Exercise{Group: "B"})
+			if assert.NotNil(t, circuit) {
+				assert.Equal(t, db.GroupCircuit, circuit.Type)
+				assert.Equal(t, 3, circuit.Rounds)
+				assert.Equal(t, "60s", circuit.RestBetweenRounds)
+			}
+		}
+
+		w = do(router, "GET", "/api/v1/programs/"+id+"/stats", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var s struct {
+			Exercises int            `json:"exercises"`
+			Sets      int            `json:"sets"`
+			Groups    map[string]int `json:"groups"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+		assert.Equal(t, 5, s.Exercises)
+		assert.Equal(t, 5+4+4+3+3, s.Sets)
+		assert.Equal(t, map[string]int{"straight": 1, "superset": 2, "circuit": 2}, s.Groups)
+	}
+
+	// regrouping keeps the day but replaces its groups and exercises
+	w = do(router, "PUT", "/api/v1/programs/"+created.ID, `{"name":"Hybrid","shared_by":"a@example.com","days":[
+		{"id":"`+created.Days[0].ID+`","name":"Push",
+		 "groups":[{"id":"`+created.Days[0].Groups[0].ID+`","label":"A","type":"giant_set","rounds":2}],
+		 "exercises":[
+			{"id":"`+created.Days[0].Exercises[1].ID+`","name":"Bench","sets":4,"reps":"8","group":"A"},
+			{"name":"Fly","sets":3,"reps":"12","group":"A"},
+			{"name":"Dips","sets":3,"reps":"10","group":"A"}]},
+		{"name":"Pull","exercises":[{"name":"Chin-up","sets":3,"reps":"5"}]}]}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	p := decode[db.Program](t, do(router, "GET", "/api/v1/programs/"+created.ID, nil))
+	assert.WithinDuration(t, created.CreatedAt, p.CreatedAt, time.Millisecond)
+	if assert.Len(t, p.Days, 2) {
+		push := p.Days[0]
+		if push.Name != "Push" {
+			push = p.Days[1]
+		}
+		if assert.Len(t, push.Groups, 1) {
+			assert.Equal(t, db.GroupGiantSet, push.Groups[0].Type)
+			assert.Equal(t, 2, push.Groups[0].Rounds)
+			assert.Empty(t, push.Groups[0].RestBetweenRounds)
+		}
+		assert.Len(t, push.Exercises, 3)
+		for _, ex := range push.Exercises {
+			assert.Equal(t, "A", ex.Group)
+		}
+	}
+	w = do(router, "GET", "/api/v1/programs/"+created.ID+"/stats", nil)
+	assert.Contains(t, w.Body.String(), `"groups":{"giant_set":3,"straight":1}`)
+}
+
+func TestLoadPrescriptions(t *testing.T) {
+	dbConn := setupDB(t)
+	router := newRouter(repos.NewGORMProgramRepo(dbConn), WithTrainingMaxes(repos.NewGORMTrainingMaxRepo(dbConn)))
+
+	send := func(method, url, body string) *httptest.ResponseRecorder {
+		return do(router, method, url, body)
+	}
+	program := func(exercise string) string {
+		return `{"name":"Strength","shared_by":"coach@example.com","days":[{"name":"Heavy","exercises":[` + exercise + `]}]}`
+	}
+
+	for name, ex := range map[string]string{
+		"load without unit": `{"name":"Squat","load":100}`,
+		"load and percent":  `{"name":"Squat","load":100,"load_unit":"kg","percent_tm":80}`,
+		"rpe and rir":       `{"name":"Squat","rpe":8,"rir":2}`,
+		"rpe out of range":  `{"name":"Squat","rpe":11}`,
+		"bad tempo":         `{"name":"Squat","tempo":"slow"}`,
+		"bad unit":          `{"name":"Squat","load":100,"load_unit":"stone"}`,
+	} {
+		w := send("POST", "/api/v1/programs", program(ex))
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+
+	w := send("POST", "/api/v1/programs", program(
+		`{"name":"Back Squat","sets":5,"reps":"5","percent_tm":80,"rpe":7.5,"tempo":"3-1-X-0"},
+		 {"name":"Front Squat","sets":3,"reps":"3","percent_tm":70},
+		 {"name":"Leg Press","sets":3,"reps":"12","load":100,"load_unit":"kg","rir":2}`))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created db.Program
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	url := "/api/v1/programs/" + created.ID
+
+	w = send("PUT", "/api/v1/training-maxes", `{"user_id":"alice","exercise":"back squat","value":150,"unit":"kg"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("PUT", "/api/v1/training-maxes", `{"user_id":"alice","exercise":"Back  Squat","value":200,"unit":"kg"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("GET", "/api/v1/training-maxes?user_id=alice", "")
+	var maxes []db.TrainingMax
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &maxes))
+	if assert.Len(t, maxes, 1, "upsert replaces the previous max") {
+		assert.Equal(t, 200.0, maxes[0].Value)
+	}
+
+	var p db.Program
+	w = send("GET", url+"?user_id=alice", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	exs := p.Days[0].Exercises
+	if assert.NotNil(t, exs[0].Resolved) {
+		assert.Equal(t, db.Resolved{Load: 160, LoadUnit: "kg", TrainingMax: 200}, *exs[0].Resolved)
+	}
+	assert.Nil(t, exs[1].Resolved, "no training max for front squat")
+	assert.Equal(t, "3-1-X-0", exs[0].Tempo)
+
+	w = send("GET", url+"?user_id=alice&unit=lb", "")
+	p = db.Program{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	exs = p.Days[0].Exercises
+	assert.Equal(t, 352.74, exs[0].Resolved.Load)
+	assert.Equal(t, "lb", exs[0].Resolved.LoadUnit)
+	assert.Equal(t, 220.46, *exs[2].Load)
+	assert.Equal(t, "lb", exs[2].LoadUnit)
+
+	// a converted program is not the stored one, so it has no ETag and a
+	// kg ETag does not validate it
+	w = send("GET", url, "")
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	w = do(router, "GET", url+"?unit=lb", nil, "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"load_unit":"lb"`)
+
+	assert.Equal(t, http.StatusBadRequest, send("GET", url+"?unit=stone", "").Code)
+}
+
+func TestSetPrescriptions(t *testing.T) {
+	dbConn := setupDB(t)
+	repo := repos.NewGORMProgramRepo(dbConn)
+	router := newRouter(repo, WithTrash(repo))
+
+	send := func(method, url, body string) *httptest.ResponseRecorder {
+		return do(router, method, url, body)
+	}
+
+	w := send("POST", "/api/v1/programs", `{"name":"Bad","shared_by":"a@example.com","days":[{"name":"Heavy","exercises":[
+		{"name":"Deadlift","set_prescriptions":[{"type":"cluster","reps":"1"}]}]}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("POST", "/api/v1/programs", `{"name":"Top Single","shared_by":"a@example.com","days":[{"name":"Heavy","exercises":[
+		{"name":"Deadlift","sets":1,"reps":"ignored","set_prescriptions":[
+			{"type":"warmup","reps":"5","percent_tm":50},
+			{"type":"working","reps":"1","percent_tm":90,"rpe":8},
+			{"type":"working","reps":"5","percent_tm":75},
+			{"type":"working","reps":"5","percent_tm":75},
+			{"type":"amrap","reps":"5+","load":140,"load_unit":"kg"}]},
+		{"name":"Curl","sets":3,"reps":"12"}]}]}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created db.Program
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = send("GET", "/api/v1/programs/"+created.ID, "")
+	var p db.Program
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	dl, curl := p.Days[0].Exercises[0], p.Days[0].Exercises[1]
+	assert.Equal(t, 4, dl.Sets)
+	assert.Equal(t, "1/5/5/5+", dl.Reps)
+	if assert.Len(t, dl.SetPrescriptions, 5) {
+		for i, set := range dl.SetPrescriptions {
+			assert.Equal(t, i, set.Position)
+		}
+		assert.Equal(t, db.SetWarmup, dl.SetPrescriptions[0].Type)
+		assert.Equal(t, 140.0, *dl.SetPrescriptions[4].Load)
+	}
+	assert.Equal(t, 3, curl.Sets, "plain exercises keep their own summary")
+	assert.Equal(t, "12", curl.Reps)
+
+	// edit the existing day and exercise in place: the stored tree must be
+	// replaced, not merged with the old one
+	w = send("PUT", "/api/v1/programs/"+created.ID, `{"name":"Top Single","shared_by":"a@example.com","days":[
+		{"id":"`+p.Days[0].ID+`","name":"Heavier","exercises":[
+			{"id":"`+dl.ID+`","name":"Squat","set_prescriptions":[
+				{"id":"`+dl.SetPrescriptions[0].ID+`","type":"working","reps":"3"},{"type":"working","reps":"3"},{"type":"drop","reps":"3"}]}]}]}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, 3, p.Days[0].Exercises[0].Sets)
+	assert.Equal(t, "3", p.Days[0].Exercises[0].Reps)
+
+	p = decode[db.Program](t, send("GET", "/api/v1/programs/"+created.ID, ""))
+	assert.WithinDuration(t, created.CreatedAt, p.CreatedAt, time.Millisecond)
+	if assert.Len(t, p.Days, 1) && assert.Len(t, p.Days[0].Exercises, 1) {
+		assert.Equal(t, "Heavier", p.Days[0].Name)
+		sq := p.Days[0].Exercises[0]
+		assert.Equal(t, "Squat", sq.Name)
+		assert.Equal(t, 3, sq.Sets)
+		assert.Equal(t, "3", sq.Reps)
+		if assert.Len(t, sq.SetPrescriptions, 3) {
+			assert.Equal(t, db.SetWorking, sq.SetPrescriptions[0].Type)
+			assert.Equal(t, "3", sq.SetPrescriptions[0].Reps)
+			assert.Nil(t, sq.SetPrescriptions[0].PercentTM)
+			assert.Equal(t, db.SetDrop, sq.SetPrescriptions[2].Type)
+		}
+	}
+	var sets int64
+	dbConn.Model(&db.SetPrescription{}).Count(&sets)
+	assert.Equal(t, int64(3), sets, "the old sets are gone")
+
+	assert.Equal(t, http.StatusNoContent, send("DELETE", "/api/v1/programs/"+created.ID, "").Code)
+	_, err := repo.Purge(context.Background(), time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	dbConn.Model(&db.SetPrescription{}).Count(&sets)
+	assert.Zero(t, sets)
+}
+
+func TestDayWarmup(t *testing.T) {
+	dbConn := setupDB(t)
+	repo := repos.NewGORMProgramRepo(dbConn)
+	maxes := repos.NewGORMTrainingMaxRepo(dbConn)
+	router := newRouter(repo, WithTrainingMaxes(maxes), WithPlateInventories(repos.NewGORMPlateInventoryRepo(dbConn)))
+
+	ctx := context.Background()
+	load := 100.0
+	pct := 80.0
+	p, err := repo.Create(ctx, &db.Program{
+		Name: "Heavy", SharedBy: "coach@example.com",
+		Days: []db.Day{{Name: "Monday", Exercises: []db.Exercise{
+			{Name: "Bench Press", Intensity: db.Intensity{Load: &load, LoadUnit: db.UnitKg}},
+			{Name: "Squat", Intensity: db.Intensity{PercentTM: &pct}},
+			{Name: "Plank"},
+		}}},
+	})
+	assert.NoError(t, err)
+	_, err = maxes.Upsert(ctx, &db.TrainingMax{UserID: "alice", Exercise: "squat", Value: 400, Unit: db.UnitLb})
+	assert.NoError(t, err)
+
+	get := func(query string) (*httptest.ResponseRecorder, warmup.DayPlan) {
+		w := do(router, "GET", "/api/v1/programs/"+p.ID+"/days/"+p.Days[0].ID+"/warmup"+query, nil)
+		var plan warmup.DayPlan
+		_ = json.Unmarshal(w.Body.Bytes(), &plan)
+		return w, plan
+	}
+
+	w, plan := get("")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "standard", plan.Template)
+	if assert.Len(t, plan.Exercises, 1, "only exercises with a working load") {
+		assert.Equal(t, []warmup.Set{
+			{Load: 20, Reps: 10, Percent: 20},
+			{Load: 40, Reps: 5, Percent: 40},
+			{Load: 60, Reps: 3, Percent: 60},
+			{Load: 80, Reps: 1, Percent: 80},
+		}, plan.Exercises[0].Sets)
+	}
+
+	w, plan = get("?user_id=alice&unit=lb&template=short")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	if assert.Len(t, plan.Exercises, 2) {
+		squat := plan.Exercises[1]
+		assert.Equal(t, 320.0, squat.WorkingLoad)
+		assert.Equal(t, "lb", squat.LoadUnit)
+		assert.Equal(t, []warmup.Set{
+			{Load: 45, Reps: 5, Percent: 14},
+			{Load: 160, Reps: 3, Percent: 50},
+			{Load: 240, Reps: 1, Percent: 75},
+		}, squat.Sets)
+	}
+
+	w, _ = get("?template=nope")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// a garage with only 45s and 25s loads in big jumps
+	w = do(router, "POST", "/api/v1/plate-inventories", `{"owner":"garage","name":"Garage","unit":"lb","bar":45,
+		"plates":[{"weight":45,"count":4},{"weight":25,"count":2}]}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var inv db.PlateInventory
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &inv))
+	w, plan = get("?unit=kg&inventory_id=" + inv.ID)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	if assert.Len(t, plan.Exercises, 1) {
+		bench := plan.Exercises[0]
+		assert.Equal(t, "lb", bench.LoadUnit, "the inventory's unit wins")
+		loads := []float64{}
+		for _, s := range bench.Sets {
+			loads = append(loads, s.Load)
+		}
+		assert.Equal(t, []float64{45, 95, 135, 185}, loads)
+	}
+	w, _ = get("?inventory_id=nope")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = do(router, "GET", "/api/v1/programs/"+p.ID+"/days/"+p.ID+"/warmup", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPlatesAndSession(t *testing.T) {
+	dbConn := setupDB(t)
+	repo := repos.NewGORMProgramRepo(dbConn)
+	router := newRouter(repo, WithPlateInventories(repos.NewGORMPlateInventoryRepo(dbConn)))
+
+	send := func(method, url, body string) *httptest.ResponseRecorder {
+		return do(router, method, url, body)
+	}
+
+	w := send("POST", "/api/v1/plates/calculate", `{"target":100}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"target":100,"achieved":100,"exact":true,"unit":"kg","bar":20,
+		"per_side":[{"weight":25,"count":1},{"weight":15,"count":1}]}`, w.Body.String())
+
+	w = send("POST", "/api/v1/plates/calculate", `{"target":0}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("POST", "/api/v1/plate-inventories", `{"owner":"garage-gym","name":"Garage","unit":"lb","bar":45,
+		"plates":[{"weight":45,"count":4},{"weight":25,"count":2},{"weight":10,"count":2}]}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var inv db.PlateInventory
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &inv))
+
+	w = send("GET", "/api/v1/plate-inventories?owner=garage-gym", "")
+	assert.Contains(t, w.Body.String(), inv.ID)
+
+	// 100 kg is 220.46 lb; the garage can only make 225
+	w = send("POST", "/api/v1/plates/calculate", `{"target":100,"unit":"kg","inventory_id":"`+inv.ID+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var b plates.Breakdown
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &b))
+	assert.False(t, b.Exact)
+	assert.Equal(t, 225.0, b.Achieved)
+	assert.Equal(t, []db.Plate{{Weight: 45, Count: 2}}, b.PerSide)
+
+	load := 60.0
+	p, err := repo.Create(context.Background(), &db.Program{
+		Name: "Session", SharedBy: "coach@example.com",
+		Days: []db.Day{{Name: "Monday", Exercises: []db.Exercise{
+			{Name: "Row", Sets: 2, Reps: "8", Intensity: db.Intensity{Load: &load, LoadUnit: db.UnitKg}},
+			{Name: "Plank", Sets: 1, Reps: "60s"},
+		}}},
+	})
+	assert.NoError(t, err)
+
+	w = send("GET", "/api/v1/programs/"+p.ID+"/days/"+p.Days[0].ID+"/session", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var day session.Day
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &day))
+	if assert.Len(t, day.Exercises, 2) {
+		row := day.Exercises[0]
+		if assert.Len(t, row.Sets, 2) && assert.NotNil(t, row.Sets[1].Plates) {
+			assert.Equal(t, 2, row.Sets[1].Number)
+			assert.Equal(t, []db.Plate{{Weight: 20, Count: 1}}, row.Sets[1].Plates.PerSide)
+		}
+		assert.Nil(t, day.Exercises[1].Sets[0].Plates)
+	}
+
+	w = send("GET", "/api/v1/programs/"+p.ID+"/days/"+p.Days[0].ID+"/session?inventory_id="+inv.ID, "")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &day))
+	assert.Equal(t, "lb", day.Exercises[0].Sets[0].LoadUnit)
+	assert.Equal(t, 135.0, day.Exercises[0].Sets[0].Plates.Achieved)
+
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v1/programs/"+p.ID+"/days/"+p.Days[0].ID+"/session?inventory_id=nope", "").Code)
+}
+
+func TestSessionHeavyLoads(t *testing.T) {
+	dbConn := setupDB(t)
+	repo := repos.NewGORMProgramRepo(dbConn)
+	router := newRouter(repo, WithPlateInventories(repos.NewGORMPlateInventoryRepo(dbConn)))
+
+	// 30 plate weights near 100, the most an inventory may hold
+	var ps []string
+	for i := range 30 {
+		ps = append(ps, fmt.Sprintf(`{"weight":%g,"count":100}`, 99.5-float64(i)/2))
+	}
+	w := do(router, "POST", "/api/v1/plate-inventories", `{"owner":"o","name":"Heavy","unit":"kg","bar":20,"plates":[`+strings.Join(ps, ",")+`]}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var inv db.PlateInventory
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &inv))
+
+	w = do(router, "POST", "/api/v1/programs", `{"name":"Heavy","shared_by":"a@example.com","days":[{"name":"A",
+		"exercises":[{"name":"Squat","sets":1,"reps":"1","load":1000000,"load_unit":"kg"}]}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	// loads stored before the limit still have to be quick to lay out
+	load, heavy := 1e6, 999.0
+	p, err := repo.Create(context.Background(), &db.Program{Name: "Heavy", SharedBy: "a@example.com", Days: []db.Day{
+		{Name: "A", Exercises: []db.Exercise{
+			{Name: "Squat", Sets: 5, Reps: "1", Intensity: db.Intensity{Load: &load, LoadUnit: db.UnitKg}},
+			{Name: "Deadlift", Sets: 5, Reps: "1", Intensity: db.Intensity{Load: &heavy, LoadUnit: db.UnitKg}},
+		}},
+	}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	w = do(router, "GET", "/api/v1/programs/"+p.ID+"/days/"+p.Days[0].ID+"/session?inventory_id="+inv.ID, nil)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var day session.Day
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &day))
+	if assert.Len(t, day.Exercises, 2) {
+		squat := day.Exercises[0].Sets[0].Plates
+		assert.False(t, squat.Exact)
+		assert.LessOrEqual(t, squat.Achieved, float64(plates.MaxTarget))
+		assert.True(t, day.Exercises[1].Sets[4].Plates.Exact, "999 is 5 plates a side")
+	}
+}
+
+func TestProgramLimits(t *testing.T) {
+	router := setupRouter(t, WithProgramLimits(2, 3), WithMiddleware(middleware.MaxBodySize(1024)))
+
+	post := func(url, body string) *httptest.ResponseRecorder {
+		return do(router, "POST", url, body)
+	}
+
+	ok := `{"name":"A","shared_by":"a@example.com","days":[{"name":"1","exercises":[{"name":"x"},{"name":"y"}]},{"name":"2","exercises":[{"name":"z"}]}]}`
+	assert.Equal(t, http.StatusCreated, post("/api/v1/programs", ok).Code)
+
+	w := post("/api/v1/programs", `{"name":"A","shared_by":"a@example.com","days":[{"name":"1"},{"name":"2"},{"name":"3"}]}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "3 days")
+
+	w = post("/api/v1/programs", `{"name":"A","shared_by":"a@example.com","days":[{"name":"1","exercises":[{"name":"x"},{"name":"y"},{"name":"z"},{"name":"w"}]}]}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = post("/api/v1/programs/batch?mode=best_effort", `[`+ok+`,{"name":"B","shared_by":"b@example.com","days":[{"name":"1"},{"name":"2"},{"name":"3"}]}]`)
+	assert.Equal(t, http.StatusMultiStatus, w.Code, w.Body.String())
+
+	// no Content-Length, so only the reader notices the body is too big
+	w = post("/api/v1/programs", `{"name":"`+strings.Repeat("x", 2048)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestProgramCache(t *testing.T) {
+	repo := repos.NewCachedProgramRepo(repos.NewGORMProgramRepo(setupDB(t)), cache.NewLRU(10), time.Minute)
+	router := newRouter(repo, WithTrash(repo), WithCacheStats(repo.Stats))
+
+	send := func(method, url, body string) *httptest.ResponseRecorder {
+		return do(router, method, url, body)
+	}
+	stats := func() cache.Stats {
+		return decode[CacheStatsResponse](t, send("GET", "/api/v1/cache/stats", "")).Programs
+	}
+
+	w := send("POST", "/api/v1/programs", `{"name":"PPL","shared_by":"a@example.com","days":[{"name":"Push",
+		"exercises":[{"name":"Bench","sets":3,"reps":"5","load":100,"load_unit":"kg"}]}]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	p := decode[db.Program](t, w)
+
+	assert.Len(t, decodeList(t, send("GET", "/api/v1/programs", "")), 1)
+	assert.Len(t, decodeList(t, send("GET", "/api/v1/programs", "")), 1)
+	send("GET", "/api/v1/programs/"+p.ID, "")
+	assert.Contains(t, send("GET", "/api/v1/programs/"+p.ID, "").Body.String(), `"PPL"`)
+	assert.Equal(t, cache.Stats{Hits: 2, Misses: 2, HitRatio: 0.5}, stats())
+
+	// writes drop the entries they affect
+	w = send("PUT", "/api/v1/programs/"+p.ID, `{"name":"PPL v2","shared_by":"a@example.com","days":[{"name":"Push",
+		"exercises":[{"name":"Bench","sets":3,"reps":"5","load":100,"load_unit":"kg"}]}]}`)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, send("GET", "/api/v1/programs/"+p.ID, "").Body.String(), `"PPL v2"`)
+	assert.Equal(t, "PPL v2", decodeList(t, send("GET", "/api/v1/programs", ""))[0].Name)
+
+	assert.Equal(t, http.StatusNoContent, send("DELETE", "/api/v1/programs/"+p.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v1/programs/"+p.ID, "").Code)
+	assert.Empty(t, decodeList(t, send("GET", "/api/v1/programs", "")))
+
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/programs/"+p.ID+"/restore", "").Code)
+	assert.Len(t, decodeList(t, send("GET", "/api/v1/programs", "")), 1)
+
+	// converting a cached program must not change the cached copy
+	assert.Contains(t, send("GET", "/api/v1/programs/"+p.ID+"?unit=lb", "").Body.String(), `"load":220.46`)
+	assert.Contains(t, send("GET", "/api/v1/programs/"+p.ID, "").Body.String(), `"load":100`)
+}
+
+func decodeList(t *testing.T, w *httptest.ResponseRecorder) []db.Program {
+	t.Helper()
+	return decode[[]db.Program](t, w)
+}
+
+func TestExportPrograms(t *testing.T) {
+	repo := repos.NewGORMProgramRepo(setupDB(t))
+	router := newRouter(repo, WithExport(repo))
+
+	export := func(ctx context.Context) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/programs/export.ndjson", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := export(context.Background())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, "0", w.Result().Trailer.Get("X-Export-Count"))
+
+	// more than one batch, plus one in the trash that must be left out
+	var ps []*db.Program
+	for i := 0; i < exportBatchSize+50; i++ {
+		ps = append(ps, &db.Program{Name: fmt.Sprintf("P%d", i), SharedBy: "a@example.com",
+			Days: []db.Day{{Name: "A", Exercises: []db.Exercise{{Name: "Squat", Sets: 5, Reps: "5"}}}}})
+	}
+	_, err := repo.CreateMany(context.Background(), ps)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Delete(context.Background(), ps[0].ID, 0))
+
+	w = export(context.Background())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "programs.ndjson")
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	assert.Len(t, lines, exportBatchSize+49)
+	seen := map[string]bool{}
+	for _, line := range lines {
+		var p db.Program
+		assert.NoError(t, json.Unmarshal([]byte(line), &p))
+		assert.Len(t, p.Days, 1)
+		assert.Len(t, p.Days[0].Exercises, 1)
+		seen[p.ID] = true
+	}
+	assert.Len(t, seen, exportBatchSize+49)
+	assert.False(t, seen[ps[0].ID])
+	assert.Equal(t, strconv.Itoa(exportBatchSize+49), w.Result().Trailer.Get("X-Export-Count"))
+
+	// a client that has gone away gets nothing
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = export(ctx)
+	assert.NotContains(t, w.Body.String(), `"days"`)
+	assert.Empty(t, w.Result().Trailer.Get("X-Export-Count"))
+}
+
+func TestTemplates(t *testing.T) {
+	dbConn := setupDB(t)
+	lib, err := templates.Load()
+	assert.NoError(t, err)
+	repo := repos.NewGORMProgramRepo(dbConn)
+	maxes := repos.NewGORMTrainingMaxRepo(dbConn)
+	router := newRouter(repo, WithTemplates(lib), WithTrainingMaxes(maxes))
+
+	send := func(method, url, body string) *httptest.ResponseRecorder {
+		return do(router, method, url, body)
+	}
+
+	w := send("GET", "/api/v1/templates", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, decode[[]templates.Summary](t, w), 5)
+
+	for _, m := range []db.TrainingMax{
+		{UserID: "u1", Exercise: "Back Squat", Value: 140, Unit: "kg"},
+		{UserID: "u1", Exercise: "Bench Press", Value: 100, Unit: "kg"},
+		{UserID: "u1", Exercise: "Deadlift", Value: 180, Unit: "kg"},
+	} {
+		_, err := maxes.Upsert(context.Background(), &m)
+		assert.NoError(t, err)
+	}
+
+	w = send("POST", "/api/v1/templates/gzclp/instantiate", `{"shared_by":"a@example.com"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "needs a user")
+	w = send("POST", "/api/v1/templates/gzclp/instantiate", `{"shared_by":"a@example.com","user_id":"u1"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "overhead press")
+
+	_, err = maxes.Upsert(context.Background(), &db.TrainingMax{UserID: "u1", Exercise: "Overhead Press", Value: 60, Unit: "kg"})
+	assert.NoError(t, err)
+	w = send("POST", "/api/v1/templates/gzclp/instantiate",
+		`{"shared_by":"a@example.com","user_id":"u1","name":"My GZCLP","days":["tue","wed","fri","sun"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	p := decode[db.Program](t, w)
+	assert.Equal(t, "My GZCLP", p.Name)
+	assert.Len(t, p.Days, 4)
+	assert.Equal(t, "Tue – Day 1", p.Days[0].Name)
+	assert.Equal(t, 120.0, *p.Days[0].Exercises[0].SetPrescriptions[0].Load, "85% of 140 kg")
+	stored, err := repo.Get(context.Background(), p.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, stored.Days[0].Exercises[0].Sets)
+
+	// Starting Strength needs no training maxes
+	w = send("POST", "/api/v1/templates/starting-strength/instantiate", `{"shared_by":"a@example.com"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = send("POST", "/api/v1/templates/starting-strength/instantiate", `{"shared_by":"a@example.com","days":["mon"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("POST", "/api/v1/templates/bro-split/instantiate", `{"shared_by":"a@example.com"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestImportProgram(t *testing.T) {
+	repo := repos.NewGORMProgramRepo(setupDB(t))
+	router := newRouter(repo, WithProgramLimits(2, 0))
+
+	post := func(query, body string) *httptest.ResponseRecorder {
+		return do(router, "POST", "/api/v1/programs/import?"+query, body, "Content-Type", "text/csv")
+	}
+	const sheet = "day,exercise,sets,reps,rest,load,unit\n" +
+		"Push,Bench Press (Barbell),4,8-10,90s,80,kg\n" +
+		"Push,Landmine Press,3,12,60s,,\n" +
+		"Pull,Chin Up,3,AMRAP,2m,,\n"
+
+	w := post("format=generic&shared_by=a@example.com&dry_run=true", sheet)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	res := decode[importers.Result](t, w)
+	assert.Equal(t, []string{"Landmine Press"}, res.Unmapped)
+	assert.Equal(t, "Bench Press", res.Program.Days[0].Exercises[0].Name)
+	assert.Empty(t, res.Program.ID)
+	list, err := repo.List(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, list, "a dry run creates nothing")
+
+	w = post("format=generic&shared_by=a@example.com&name=Mine", sheet)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	res = decode[importers.Result](t, w)
+	stored, err := repo.Get(context.Background(), res.Program.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Mine", stored.Name)
+	assert.Len(t, stored.Days, 2)
+
+	w = post("format=generic&shared_by=a@example.com", sheet+"Legs,Squat,5,5,3m,,\n")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	w = post("format=generic&shared_by=a@example.com", "day,exercise,sets,reps,rpe\nA,B,3,5,11\n")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = post("format=strong&shared_by=a@example.com", sheet)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "missing columns")
+	w = post("format=fitbod&shared_by=a@example.com", sheet)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPrintProgram(t *testing.T) {
+	dbConn := setupDB(t)
+	repo := repos.NewGORMProgramRepo(dbConn)
+	maxes := repos.NewGORMTrainingMaxRepo(dbConn)
+	router := newRouter(repo, WithTrainingMaxes(maxes), WithPublicURL("https://dyel.example.com/"))
+
+	pct := 80.0
+	p, err := repo.Create(context.Background(), &db.Program{Name: "Squat Focus", SharedBy: "a@example.com", Days: []db.Day{
+		{Name: "Heavy", Exercises: []db.Exercise{{Name: "Back Squat", Sets: 5, Reps: "3", Rest: "3m", Intensity: db.Intensity{PercentTM: &pct}}}},
+		{Name: "Light", Exercises: []db.Exercise{{Name: "Front Squat", Sets: 3, Reps: "5"}}},
+	}})
+	assert.NoError(t, err)
+	_, err = maxes.Upsert(context.Background(), &db.TrainingMax{UserID: "u1", Exercise: "Back Squat", Value: 150, Unit: "kg"})
+	assert.NoError(t, err)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		return do(router, "GET", url, nil)
+	}
+
+	w := get("/api/v1/programs/" + p.ID + "/print?user_id=u1")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "80% TM (120 kg)")
+	assert.Contains(t, w.Body.String(), "https://dyel.example.com/programs/"+p.ID)
+	assert.Equal(t, 2, strings.Count(w.Body.String(), "<section>"))
+
+	w = get("/api/v1/programs/" + p.ID + "/print?format=pdf")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "%PDF-"))
+
+	req := httptest.NewRequest("GET", "/api/v1/programs/"+p.ID+"/print", nil)
+	req.Host = "evil.example.com"
+	w = httptest.NewRecorder()
+	newRouter(repo).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "evil.example.com", "no PublicURL, no link")
+	assert.NotContains(t, w.Body.String(), `class="qr"`)
+
+	w = get("/api/v1/programs/" + p.ID + "/print?format=docx")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = get("/api/v1/programs/missing/print")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetProgramFormats(t *testing.T) {
+	repo := repos.NewGORMProgramRepo(setupDB(t))
+	router := newRouter(repo)
+
+	p, err := repo.Create(context.Background(), &db.Program{Name: "Push", SharedBy: "a@example.com", Days: []db.Day{
+		{Name: "Day 1", Exercises: []db.Exercise{{Name: "Bench Press", Sets: 4, Reps: "8-10", Rest: "90s"}}},
+	}})
+	assert.NoError(t, err)
+
+	get := func(url, accept string) *httptest.ResponseRecorder {
+		if accept == "" {
+			return do(router, "GET", url, nil)
+		}
+		return do(router, "GET", url, nil, "Accept", accept)
+	}
+
+	w := get("/api/v1/programs/"+p.ID+"?format=markdown&unit=lb", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "## Day 1\n\n- Bench Press — 4×8-10, rest 90s\n")
+
+	w = get("/api/v1/programs/"+p.ID, "text/markdown")
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+
+	w = get("/api/v1/programs/"+p.ID+"?format=text", "")
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Push\nShared by a@example.com\n\nDay 1\n  Bench Press — 4×8-10, rest 90s\n", w.Body.String())
+
+	w = get("/api/v1/programs/"+p.ID, "text/html,*/*;q=0.8")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	w = get("/api/v1/programs/"+p.ID+"?format=yaml", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// checkSize enforces MaxDays and MaxExercises on a program's days.
+func (h *Handler) checkSize(days []db.Day) error {
+	if err := db.CheckSize(days, h.MaxDays, h.MaxExercises); err != nil {
+		return &StatusError{http.StatusRequestEntityTooLarge, err}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+	"github.com/iraunchy/dyel/backend/internal/importers"
+	"github.com/iraunchy/dyel/backend/internal/openapi"
+	"github.com/iraunchy/dyel/backend/internal/plates"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+	"github.com/iraunchy/dyel/backend/internal/session"
+	"github.com/iraunchy/dyel/backend/internal/stats"
+	"github.com/iraunchy/dyel/backend/internal/templates"
+	"github.com/iraunchy/dyel/backend/internal/warmup"
+)
+
+const apiPrefix = "/api/v1"
+
+//go:embed assets/docs.html
+var docsHTML []byte
+
+// preconditionErrors are the extra outcomes of an If-Match guarded write.
+var preconditionErrors = []int{
+	http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired,
+}
+
+// writeErrors are the responses of program writes with a body.
+var writeErrors = append([]int{http.StatusRequestEntityTooLarge}, preconditionErrors...)
+
+// operations documents every route under /api/v1. openapi.Build refuses to
+// produce a spec while a registered route is missing from this table.
+var operations = map[string]openapi.Operation{
+	openapi.Key(http.MethodPost, "/api/v1/programs"): {
+		ID: "createProgram", Summary: "Create a program", Tag: "programs",
+		Body: CreateProgramInput{}, Response: db.Program{}, Status: http.StatusCreated,
+		Extra: []int{http.StatusRequestEntityTooLarge},
+	},
+	openapi.Key(http.MethodPost, "/api/v1/programs/batch"): {
+		ID: "batchCreatePrograms", Summary: "Create many programs, atomically or best-effort", Tag: "programs",
+		Query: BatchQuery{}, Body: []CreateProgramInput{}, Response: BatchResult{}, Status: http.StatusCreated,
+		Extra: []int{http.StatusMultiStatus, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity},
+	},
+	openapi.Key(http.MethodPost, "/api/v1/programs/import"): {
+		ID: "importProgram", Summary: "Create a program from a Strong, Hevy or spreadsheet CSV export", Tag: "programs",
+		Query: ImportQuery{}, Body: "", BodyType: "text/csv", Response: importers.Result{}, Status: http.StatusCreated,
+		Extra: []int{http.StatusOK, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs"): {
+		ID: "listPrograms", Summary: "List programs", Tag: "programs",
+		Response: []db.Program{},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs/:id"): {
+		ID: "getProgram", Summary: "Get a program, as JSON or (format=markdown|text) Markdown or plain text", Tag: "programs",
+		URI: GetProgramInput{}, Query: GetProgramQuery{}, Header: IfNoneMatchHeader{}, Response: db.Program{},
+		Extra: []int{http.StatusNotModified, http.StatusNotFound},
+	},
+	openapi.Key(http.MethodPut, "/api/v1/programs/:id"): {
+		ID: "updateProgram", Summary: "Replace a program", Tag: "programs",
+		URI: UpdateProgramURI{}, Header: IfMatchHeader{}, Body: UpdateProgramJSON{}, Response: db.Program{},
+		Extra: writeErrors,
+	},
+	openapi.Key(http.MethodPatch, "/api/v1/programs/:id"): {
+		ID: "patchProgram", Summary: "Change some fields of a program", Tag: "programs",
+		URI: UpdateProgramURI{}, Header: IfMatchHeader{}, Body: PatchProgramJSON{}, Response: db.Program{},
+		Extra: writeErrors,
+	},
+	openapi.Key(http.MethodDelete, "/api/v1/programs/:id"): {
+		ID: "deleteProgram", Summary: "Move a program to the trash", Tag: "programs",
+		URI: GetProgramInput{}, Header: IfMatchHeader{}, Status: http.StatusNoContent,
+		Extra: preconditionErrors,
+	},
+	openapi.Key(http.MethodPost, "/api/v1/programs/:id/fork"): {
+		ID: "forkProgram", Summary: "Fork a program into an independent copy", Tag: "programs",
+		URI: ForkProgramURI{}, Body: ForkProgramJSON{}, Response: db.Program{}, Status: http.StatusCreated,
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs/:id/stats"): {
+		ID: "getProgramStats", Summary: "Summarise a program's days, sets and exercise groups", Tag: "programs",
+		URI: GetProgramInput{}, Response: stats.ProgramStats{},
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs/:id/print"): {
+		ID: "printProgram", Summary: "A printable sheet with a page per day, as HTML or (format=pdf) PDF", Tag: "programs",
+		URI: GetProgramInput{}, Query: PrintQuery{}, Response: "", ContentType: "text/html",
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs/:id/days/:dayId/warmup"): {
+		ID: "getDayWarmup", Summary: "Calculate warm-up ramps for a day's working loads", Tag: "programs",
+		URI: DayURI{}, Query: WarmupQuery{}, Response: warmup.DayPlan{},
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs/:id/days/:dayId/session"): {
+		ID: "getDaySession", Summary: "Lay a day out set by set with plate loading", Tag: "programs",
+		URI: DayURI{}, Query: SessionQuery{}, Response: session.Day{},
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodPost, "/api/v1/plates/calculate"): {
+		ID: "calculatePlates", Summary: "Work out the plates to load for a target weight", Tag: "plates",
+		Body: CalculatePlatesInput{}, Response: plates.Breakdown{},
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodPost, "/api/v1/plate-inventories"): {
+		ID: "createPlateInventory", Summary: "Store a user's or gym's plate inventory", Tag: "plates",
+		Body: CreatePlateInventoryInput{}, Response: db.PlateInventory{}, Status: http.StatusCreated,
+	},
+	openapi.Key(http.MethodGet, "/api/v1/plate-inventories"): {
+		ID: "listPlateInventories", Summary: "List the plate inventories of a user or gym", Tag: "plates",
+		Query: ListPlateInventoriesInput{}, Response: []db.PlateInventory{},
+	},
+	openapi.Key(http.MethodDelete, "/api/v1/plate-inventories/:id"): {
+		ID: "deletePlateInventory", Summary: "Remove a plate inventory", Tag: "plates",
+		URI: DeletePlateInventoryInput{}, Status: http.StatusNoContent,
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs/trash"): {
+		ID: "listTrash", Summary: "List deleted programs that can still be restored", Tag: "trash",
+		Response: []db.Program{},
+	},
+	openapi.Key(http.MethodPost, "/api/v1/programs/:id/restore"): {
+		ID: "restoreProgram", Summary: "Restore a deleted program", Tag: "trash",
+		URI: RestoreProgramInput{}, Response: db.Program{},
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/search"): {
+		ID: "searchPrograms", Summary: "Full-text search across programs, days and exercises", Tag: "programs",
+		Query: SearchInput{}, Response: []repos.SearchHit{},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/training-maxes"): {
+		ID: "listTrainingMaxes", Summary: "List a user's training maxes", Tag: "training-maxes",
+		Query: ListTrainingMaxesInput{}, Response: []db.TrainingMax{},
+	},
+	openapi.Key(http.MethodPut, "/api/v1/training-maxes"): {
+		ID: "putTrainingMax", Summary: "Set a user's training max for an exercise", Tag: "training-maxes",
+		Body: PutTrainingMaxInput{}, Response: db.TrainingMax{},
+	},
+	openapi.Key(http.MethodDelete, "/api/v1/training-maxes/:id"): {
+		ID: "deleteTrainingMax", Summary: "Remove a training max", Tag: "training-maxes",
+		URI: DeleteTrainingMaxInput{}, Status: http.StatusNoContent,
+		Extra: []int{http.StatusNotFound},
+	},
+	openapi.Key(http.MethodPost, "/api/v1/webhooks"): {
+		ID: "createWebhook", Summary: "Register a webhook endpoint", Tag: "webhooks",
+		Body: CreateWebhookInput{}, Response: CreatedWebhook{}, Status: http.StatusCreated,
+	},
+	openapi.Key(http.MethodGet, "/api/v1/webhooks"): {
+		ID: "listWebhooks", Summary: "List webhook endpoints", Tag: "webhooks",
+		Response: []db.WebhookEndpoint{},
+	},
+	openapi.Key(http.MethodDelete, "/api/v1/webhooks/:id"): {
+		ID: "deleteWebhook", Summary: "Remove a webhook endpoint", Tag: "webhooks",
+		URI: DeleteWebhookInput{}, Status: http.StatusNoContent,
+	},
+	openapi.Key(http.MethodGet, "/api/v1/templates"): {
+		ID: "listTemplates", Summary: "List the built-in program templates", Tag: "templates",
+		Response: []templates.Summary{},
+	},
+	openapi.Key(http.MethodPost, "/api/v1/templates/:slug/instantiate"): {
+		ID: "instantiateTemplate", Summary: "Create a program from a template and the lifter's training maxes", Tag: "templates",
+		URI: TemplateURI{}, Body: InstantiateTemplateJSON{}, Response: db.Program{}, Status: http.StatusCreated,
+		Extra: []int{http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/programs/export.ndjson"): {
+		ID: "exportPrograms", Summary: "Stream every program, one JSON object per line", Tag: "programs",
+		Response: db.Program{}, ContentType: "application/x-ndjson",
+	},
+	openapi.Key(http.MethodGet, "/api/v1/cache/stats"): {
+		ID: "getCacheStats", Summary: "Program cache hits, misses and errors", Tag: "meta",
+		Response: CacheStatsResponse{},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/openapi.json"): {
+		ID: "getOpenAPI", Summary: "This OpenAPI document", Tag: "meta",
+		Response: map[string]any{},
+	},
+	openapi.Key(http.MethodGet, "/api/v1/docs"): {
+		ID: "getDocs", Summary: "Interactive API documentation", Tag: "meta",
+		Response: "", ContentType: "text/html",
+	},
+}
+
+// OpenAPISpec handles GET /api/v1/openapi.json. The document is built once,
+// on first request, from the routes registered on r.
+func (h *Handler) OpenAPISpec(r *gin.Engine) gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *openapi.Document
+		err  error
+	)
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc, err = openapi.Build(
+				openapi.Info{Title: "DYEL API", Version: "1.0.0"},
+				apiRoutes(r.Routes()),
+				operations,
+			)
+		})
+		if err != nil {
+			httpresp.Error(c, http.StatusInternalServerError, err)
+			return
+		}
+		httpresp.JSON(c, http.StatusOK, doc)
+	}
+}
+
+// docsCSP lets the docs page run its one inline script and stylesheet,
+// identified by hash, and fetch the spec from the same origin.
+var docsCSP = "default-src 'none'; script-src '" + inlineHash(docsHTML, "script") + "'; " +
+	"style-src '" + inlineHash(docsHTML, "style") + "'; connect-src 'self'; " +
+	"base-uri 'none'; frame-ancestors 'none'"
+
+// APIDocs handles GET /api/v1/docs
+func (h *Handler) APIDocs(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+}
+
+// inlineHash returns the CSP source for the first inline <tag> element in
+// page.
+func inlineHash(page []byte, tag string) string {
+	_, rest, _ := bytes.Cut(page, []byte("<"+tag+">"))
+	body, _, _ := bytes.Cut(rest, []byte("</"+tag+">"))
+	sum := sha256.Sum256(body)
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// apiRoutes keeps only the routes under /api/v1.
+func apiRoutes(routes gin.RoutesInfo) gin.RoutesInfo {
+	var out gin.RoutesInfo
+	for _, r := range routes {
+		if strings.HasPrefix(r.Path, apiPrefix+"/") {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iraunchy/dyel/backend/internal/cache"
+	"github.com/iraunchy/dyel/backend/internal/openapi"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+	"github.com/iraunchy/dyel/backend/internal/templates"
+)
+
+// specPath is the committed contract consumed by API clients, and
+// typesPath the frontend's TypeScript types generated from it.
+const (
+	specPath  = "../../api/openapi.json"
+	typesPath = "../../../frontend/src/api/types.ts"
+)
+
+var update = flag.Bool("update", false, "rewrite "+specPath+" and "+typesPath+" from the code")
+
+const typesHeader = "// Code generated from backend/api/openapi.json by\n" +
+	"// `go test ./internal/handlers -run OpenAPI -update`. DO NOT EDIT.\n"
+
+// TestOpenAPISpecUpToDate fails when routes or input types change without
+// the committed spec and frontend types being regenerated with
+// `go test ./internal/handlers -update`.
+func TestOpenAPISpecUpToDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lib, err := templates.Load()
+	assert.NoError(t, err)
+
+	h := NewHandler(&mockRepo{},
+		WithWebhooks(repos.NewGORMWebhookRepo(nil)),
+		WithSearch(repos.NewGORMSearchRepo(nil)),
+		WithTrash(repos.NewGORMProgramRepo(nil)),
+		WithTrainingMaxes(repos.NewGORMTrainingMaxRepo(nil)),
+		WithPlateInventories(repos.NewGORMPlateInventoryRepo(nil)),
+		WithCacheStats(func() cache.Stats { return cache.Stats{} }),
+		WithExport(repos.NewGORMProgramRepo(nil)),
+		WithTemplates(lib),
+	)
+	router := gin.New()
+	h.RegisterRoutes(router)
+
+	assert.Empty(t, openapi.Unrouted(router.Routes(), operations),
+		"operations documented for routes that no longer exist")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var pretty bytes.Buffer
+	assert.NoError(t, json.Indent(&pretty, w.Body.Bytes(), "", "  "))
+	pretty.WriteByte('\n')
+
+	var doc openapi.Document
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	types := openapi.TypeScript(&doc, typesHeader)
+
+	// responses promise what they always send; request bodies don't
+	assert.Subset(t, doc.Components.Schemas["Program"].Required, []string{"id", "name", "created_at", "days"})
+	assert.Contains(t, string(types), "  id: string\n")
+	if assert.Contains(t, doc.Components.Schemas, "DayInput") {
+		assert.Empty(t, doc.Components.Schemas["DayInput"].Required)
+	}
+
+	if *update {
+		assert.NoError(t, os.WriteFile(specPath, pretty.Bytes(), 0o644))
+		assert.NoError(t, os.WriteFile(typesPath, types, 0o644))
+		return
+	}
+
+	committed, err := os.ReadFile(specPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(committed), pretty.String(),
+		"api/openapi.json is stale; run `go test ./internal/handlers -run OpenAPI -update`")
+	committed, err = os.ReadFile(typesPath)
+	assert.NoError(t, err)
+	assert.Equal(t, string(committed), string(types),
+		"frontend/src/api/types.ts is stale; run `go test ./internal/handlers -run OpenAPI -update`")
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/plates"
+)
+
+// CalculatePlates handles POST /api/v1/plates/calculate
+func (h *Handler) CalculatePlates(c *gin.Context) {
+	HandleJSON[CalculatePlatesInput, plates.Breakdown](
+		c,
+		BindJSON[CalculatePlatesInput],
+		func(ctx context.Context, in CalculatePlatesInput) (plates.Breakdown, error) {
+			inv, err := h.inventory(ctx, in.InventoryID, in.Unit)
+			if err != nil {
+				return plates.Breakdown{}, err
+			}
+			if in.Bar != nil {
+				inv.Bar = *in.Bar
+			}
+			if len(in.Plates) > 0 {
+				inv.Plates = in.Plates
+			}
+			target := in.Target
+			if in.Unit != "" {
+				target = db.ConvertLoad(target, in.Unit, inv.Unit)
+			}
+			return plates.Calculate(target, inv), nil
+		},
+		http.StatusOK,
+	)
+}
+
+// CreatePlateInventory handles POST /api/v1/plate-inventories
+func (h *Handler) CreatePlateInventory(c *gin.Context) {
+	HandleJSON[CreatePlateInventoryInput, *db.PlateInventory](
+		c,
+		BindJSON[CreatePlateInventoryInput],
+		func(ctx context.Context, in CreatePlateInventoryInput) (*db.PlateInventory, error) {
+			return h.Plates.Create(ctx, in.ToModel())
+		},
+		http.StatusCreated,
+	)
+}
+
+// ListPlateInventories handles GET /api/v1/plate-inventories?owner=...
+func (h *Handler) ListPlateInventories(c *gin.Context) {
+	HandleJSON[ListPlateInventoriesInput, []db.PlateInventory](
+		c,
+		BindQuery[ListPlateInventoriesInput],
+		func(ctx context.Context, in ListPlateInventoriesInput) ([]db.PlateInventory, error) {
+			return h.Plates.List(ctx, in.Owner)
+		},
+		http.StatusOK,
+	)
+}
+
+// DeletePlateInventory handles DELETE /api/v1/plate-inventories/:id
+func (h *Handler) DeletePlateInventory(c *gin.Context) {
+	HandleJSON[DeletePlateInventoryInput, struct{}](
+		c,
+		BindURI[DeletePlateInventoryInput],
+		func(ctx context.Context, in DeletePlateInventoryInput) (struct{}, error) {
+			return struct{}{}, h.Plates.Delete(ctx, in.ID)
+		},
+		http.StatusNoContent,
+	)
+}
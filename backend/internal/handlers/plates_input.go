@@ -0,0 +1,45 @@
+package handlers
+
+import "github.com/iraunchy/dyel/backend/db"
+
+// CalculatePlatesInput maps the JSON body for POST /plates/calculate.
+// Target is in Unit. The bar and plates come from InventoryID, or the
+// standard inventory for Unit, and Bar and Plates override either.
+type CalculatePlatesInput struct {
+	Target      float64    `json:"target" binding:"required,gt=0,lte=1000"`
+	Unit        string     `json:"unit" binding:"omitempty,oneof=kg lb"`
+	InventoryID string     `json:"inventory_id"`
+	Bar         *float64   `json:"bar" binding:"omitempty,gte=0,lte=100"`
+	Plates      []db.Plate `json:"plates" binding:"omitempty,max=30,dive"`
+}
+
+// CreatePlateInventoryInput maps the JSON body for POST /plate-inventories.
+type CreatePlateInventoryInput struct {
+	Owner  string     `json:"owner" binding:"required"`
+	Name   string     `json:"name" binding:"required"`
+	Unit   string     `json:"unit" binding:"required,oneof=kg lb"`
+	Bar    float64    `json:"bar" binding:"gte=0,lte=100"`
+	Plates []db.Plate `json:"plates" binding:"required,min=1,max=30,dive"`
+}
+
+// ToModel converts CreatePlateInventoryInput → *db.PlateInventory
+func (in CreatePlateInventoryInput) ToModel() *db.PlateInventory {
+	return &db.PlateInventory{
+		Owner:  in.Owner,
+		Name:   in.Name,
+		Unit:   in.Unit,
+		Bar:    in.Bar,
+		Plates: in.Plates,
+	}
+}
+
+// ListPlateInventoriesInput maps the query for GET /plate-inventories.
+type ListPlateInventoriesInput struct {
+	Owner string `form:"owner" binding:"required"`
+}
+
+// DeletePlateInventoryInput holds the :id param for
+// DELETE /plate-inventories/:id.
+type DeletePlateInventoryInput struct {
+	ID string `uri:"id" binding:"required"`
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+	"github.com/iraunchy/dyel/backend/internal/printsheet"
+)
+
+// PrintProgram handles GET /api/v1/programs/:id/print, a sheet to train
+// from with a page per day, as HTML or PDF. With a PublicURL, its QR code
+// leads to the program in the web app.
+func (h *Handler) PrintProgram(c *gin.Context) {
+	in, err := BindURI[GetProgramInput](c)
+	if err != nil {
+		httpresp.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	q, err := BindQuery[PrintQuery](c)
+	if err != nil {
+		httpresp.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	p, err := h.Repo.Get(ctx, in.ID)
+	if err != nil {
+		httpresp.Error(c, statusFor(err, http.StatusInternalServerError), err)
+		return
+	}
+	if _, err := h.personalise(ctx, p, GetProgramQuery{UserID: q.UserID, Unit: q.Unit}); err != nil {
+		httpresp.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	sheet, err := printsheet.New(p, h.programLink(p.ID))
+	if err != nil {
+		httpresp.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Render fully before answering, so a failure is still a JSON error.
+	var out bytes.Buffer
+	if q.Format == PrintPDF {
+		err = sheet.PDF(&out)
+	} else {
+		err = sheet.HTML(&out)
+	}
+	if err != nil {
+		httpresp.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	if q.Format == PrintPDF {
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="program-%s.pdf"`, p.ID))
+		c.Data(http.StatusOK, "application/pdf", out.Bytes())
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", out.Bytes())
+}
+
+// programLink is the web app's page for program id under PublicURL, or
+// empty when PublicURL is unset. The request's Host header is up to the
+// client, so it is never used to build the link.
+func (h *Handler) programLink(id string) string {
+	if h.PublicURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(h.PublicURL, "/") + "/programs/" + id
+}
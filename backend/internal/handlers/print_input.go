@@ -0,0 +1,16 @@
+package handlers
+
+// Formats of GET /programs/:id/print.
+const (
+	PrintHTML = "html"
+	PrintPDF  = "pdf"
+)
+
+// PrintQuery maps the query string for GET /programs/:id/print. Format is
+// html unless given; UserID and Unit print loads as GET /programs/:id
+// returns them.
+type PrintQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=html pdf"`
+	UserID string `form:"user_id"`
+	Unit   string `form:"unit" binding:"omitempty,oneof=kg lb"`
+}
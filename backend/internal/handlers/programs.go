@@ -6,12 +6,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/iraunchy/dyel/backend/db"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+	"github.com/iraunchy/dyel/backend/internal/loads"
+	"github.com/iraunchy/dyel/backend/internal/render"
+	"github.com/iraunchy/dyel/backend/internal/repos"
 )
 
 func (h *Handler) CreateProgram(c *gin.Context) {
 	HandleJSON[CreateProgramInput, *db.Program](
 		c,
-		BindJSON[CreateProgramInput],
+		func(c *gin.Context) (CreateProgramInput, error) {
+			in, err := BindJSON[CreateProgramInput](c)
+			if err != nil {
+				return in, err
+			}
+			return in, h.checkSize(in.Days)
+		},
 		func(ctx context.Context, in CreateProgramInput) (*db.Program, error) {
 			return h.Repo.Create(ctx, in.ToModel())
 		},
@@ -33,16 +43,88 @@ func (h *Handler) ListPrograms(c *gin.Context) {
 	)
 }
 
-// GetProgram handles GET /api/v1/programs/:id
+// GetProgram handles GET /api/v1/programs/:id. It sets an ETag and answers
+// 304 when If-None-Match already holds the current version. Responses
+// personalised with ?user_id= depend on the user's training maxes as well,
+// so they carry no ETag.
 func (h *Handler) GetProgram(c *gin.Context) {
-	HandleJSON[GetProgramInput, *db.Program](
-		c,
-		BindURI[GetProgramInput],
-		func(ctx context.Context, in GetProgramInput) (*db.Program, error) {
-			return h.Repo.Get(ctx, in.ID)
-		},
-		http.StatusOK,
-	)
+	in, err := BindURI[GetProgramInput](c)
+	if err != nil {
+		httpresp.Error(c, http.StatusBadRequest, err)
+		return
+	}
+	q, err := BindQuery[GetProgramQuery](c)
+	if err != nil {
+		httpresp.Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	p, err := h.Repo.Get(ctx, in.ID)
+	if err != nil {
+		httpresp.Error(c, statusFor(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	personal, err := h.personalise(ctx, p, q)
+	if err != nil {
+		httpresp.Error(c, http.StatusInternalServerError, err)
+		return
+	}
+	switch programFormat(c, q.Format) {
+	case ProgramMarkdown:
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(render.Markdown(p)))
+		return
+	case ProgramText:
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(render.Text(p)))
+		return
+	}
+	if personal {
+		httpresp.JSON(c, http.StatusOK, p)
+		return
+	}
+
+	setETag(c, p)
+	if hdr, _ := BindHeader[IfNoneMatchHeader](c); hdr.IfNoneMatch != "" && etagMatches(hdr.IfNoneMatch, programETag(p)) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	httpresp.JSON(c, http.StatusOK, p)
+}
+
+// programFormat picks how GetProgram writes a program: the format query
+// parameter when given, else the best match for the Accept header.
+func programFormat(c *gin.Context, format string) string {
+	if format != "" {
+		return format
+	}
+	c.Header("Vary", "Accept")
+	switch c.NegotiateFormat(gin.MIMEJSON, "text/markdown", gin.MIMEPlain) {
+	case "text/markdown":
+		return ProgramMarkdown
+	case gin.MIMEPlain:
+		return ProgramText
+	}
+	return "json"
+}
+
+// personalise resolves p's percentage loads against the training maxes of
+// q.UserID and converts its loads to q.Unit. It reports whether p now
+// differs from the stored program, in which case the program's ETag must
+// not be sent with it.
+func (h *Handler) personalise(ctx context.Context, p *db.Program, q GetProgramQuery) (bool, error) {
+	resolved := q.UserID != "" && h.TrainingMaxes != nil
+	if resolved {
+		maxes, err := h.TrainingMaxes.List(ctx, q.UserID)
+		if err != nil {
+			return false, err
+		}
+		loads.Resolve(p, maxes)
+	}
+	if q.Unit != "" {
+		loads.Convert(p, q.Unit)
+	}
+	return resolved || q.Unit != "", nil
 }
 
 // UpdateProgram handles PUT /api/v1/programs/:id
@@ -54,11 +136,60 @@ func (h *Handler) UpdateProgram(c *gin.Context) {
 			if err != nil {
 				return UpdateProgramInput{}, err
 			}
+			version, err := h.versionFromIfMatch(c)
+			if err != nil {
+				return UpdateProgramInput{}, err
+			}
 			body, err := BindJSON[UpdateProgramJSON](c)
-			return uri.Merge(body), err
+			if err == nil {
+				err = h.checkSize(body.Days)
+			}
+			in := uri.Merge(body)
+			in.Version = version
+			return in, err
 		},
 		func(ctx context.Context, in UpdateProgramInput) (*db.Program, error) {
-			return h.Repo.Update(ctx, in.ToModel())
+			p, err := h.Repo.Update(ctx, in.ToModel())
+			setETag(c, p)
+			return p, err
+		},
+		http.StatusOK,
+	)
+}
+
+// PatchProgram handles PATCH /api/v1/programs/:id. Without If-Match the
+// version read here guards the write, so a concurrent change still yields 412.
+func (h *Handler) PatchProgram(c *gin.Context) {
+	HandleJSON[PatchProgramInput, *db.Program](
+		c,
+		func(c *gin.Context) (PatchProgramInput, error) {
+			uri, err := BindURI[UpdateProgramURI](c)
+			if err != nil {
+				return PatchProgramInput{}, err
+			}
+			version, err := h.versionFromIfMatch(c)
+			if err != nil {
+				return PatchProgramInput{}, err
+			}
+			body, err := BindJSON[PatchProgramJSON](c)
+			if err == nil && body.Days != nil {
+				err = h.checkSize(*body.Days)
+			}
+			return PatchProgramInput{ID: uri.ID, Patch: body, Version: version}, err
+		},
+		func(ctx context.Context, in PatchProgramInput) (*db.Program, error) {
+			p, err := h.Repo.Get(ctx, in.ID)
+			if err != nil {
+				return nil, err
+			}
+			if in.Version != 0 && in.Version != p.Version {
+				return nil, repos.ErrVersionConflict
+			}
+			in.Patch.Apply(p)
+
+			p, err = h.Repo.Update(ctx, p)
+			setETag(c, p)
+			return p, err
 		},
 		http.StatusOK,
 	)
@@ -66,13 +197,14 @@ func (h *Handler) UpdateProgram(c *gin.Context) {
 
 // DeleteProgram handles DELETE /api/v1/programs/:id
 func (h *Handler) DeleteProgram(c *gin.Context) {
-	HandleJSON[string, struct{}](
+	HandleJSON[DeleteProgramInput, struct{}](
 		c,
-		func(c *gin.Context) (string, error) {
-			return c.Param("id"), nil
+		func(c *gin.Context) (DeleteProgramInput, error) {
+			version, err := h.versionFromIfMatch(c)
+			return DeleteProgramInput{ID: c.Param("id"), Version: version}, err
 		},
-		func(ctx context.Context, id string) (struct{}, error) {
-			if err := h.Repo.Delete(ctx, id); err != nil {
+		func(ctx context.Context, in DeleteProgramInput) (struct{}, error) {
+			if err := h.Repo.Delete(ctx, in.ID, in.Version); err != nil {
 				return struct{}{}, err
 			}
 			return struct{}{}, nil
@@ -80,3 +212,50 @@ func (h *Handler) DeleteProgram(c *gin.Context) {
 		http.StatusNoContent,
 	)
 }
+
+// ForkProgram handles POST /api/v1/programs/:id/fork
+func (h *Handler) ForkProgram(c *gin.Context) {
+	HandleJSON[ForkProgramInput, *db.Program](
+		c,
+		func(c *gin.Context) (ForkProgramInput, error) {
+			uri, err := BindURI[ForkProgramURI](c)
+			if err != nil {
+				return ForkProgramInput{}, err
+			}
+			body, err := BindJSON[ForkProgramJSON](c)
+			return uri.Merge(body), err
+		},
+		func(ctx context.Context, in ForkProgramInput) (*db.Program, error) {
+			return h.Repo.Fork(ctx, in.ID, in.SharedBy)
+		},
+		http.StatusCreated,
+	)
+}
+
+// ListTrash handles GET /api/v1/programs/trash
+func (h *Handler) ListTrash(c *gin.Context) {
+	HandleJSON[ListProgramsInput, []db.Program](
+		c,
+		func(c *gin.Context) (ListProgramsInput, error) {
+			return ListProgramsInput{}, nil
+		},
+		func(ctx context.Context, _ ListProgramsInput) ([]db.Program, error) {
+			return h.Trash.ListTrash(ctx)
+		},
+		http.StatusOK,
+	)
+}
+
+// RestoreProgram handles POST /api/v1/programs/:id/restore
+func (h *Handler) RestoreProgram(c *gin.Context) {
+	HandleJSON[RestoreProgramInput, *db.Program](
+		c,
+		BindURI[RestoreProgramInput],
+		func(ctx context.Context, in RestoreProgramInput) (*db.Program, error) {
+			p, err := h.Trash.Restore(ctx, in.ID)
+			setETag(c, p)
+			return p, err
+		},
+		http.StatusOK,
+	)
+}
@@ -10,11 +10,27 @@ type GetProgramInput struct {
 	ID string `uri:"id" binding:"required"`
 }
 
+// Formats GET /programs/:id writes besides JSON.
+const (
+	ProgramMarkdown = "markdown"
+	ProgramText     = "text"
+)
+
+// GetProgramQuery personalises GET /programs/:id: UserID resolves
+// percentage loads against that user's training maxes and Unit converts
+// every load to kg or lb. Format asks for Markdown or plain text instead of
+// JSON; without it, an Accept header of text/markdown or text/plain does.
+type GetProgramQuery struct {
+	UserID string `form:"user_id"`
+	Unit   string `form:"unit" binding:"omitempty,oneof=kg lb"`
+	Format string `form:"format" binding:"omitempty,oneof=json markdown text"`
+}
+
 // CreateProgramInput maps the JSON body for POST /programs.
 type CreateProgramInput struct {
 	Name     string   `json:"name" binding:"required"`
 	SharedBy string   `json:"shared_by" binding:"required"`
-	Days     []db.Day `json:"days" binding:"required"`
+	Days     []db.Day `json:"days" binding:"required,dive"`
 }
 
 // ToModel converts CreateProgramInput → *db.Program
@@ -26,12 +42,14 @@ func (in CreateProgramInput) ToModel() *db.Program {
 	}
 }
 
-// UpdateProgramInput merges ID+body and already has ToModel()
+// UpdateProgramInput merges ID+body and already has ToModel().
+// Version comes from If-Match; zero means unconditional.
 type UpdateProgramInput struct {
 	ID       string
 	Name     string
 	SharedBy string
 	Days     []db.Day
+	Version  int
 }
 
 func (in UpdateProgramInput) ToModel() *db.Program {
@@ -40,6 +58,7 @@ func (in UpdateProgramInput) ToModel() *db.Program {
 		Name:     in.Name,
 		SharedBy: in.SharedBy,
 		Days:     in.Days,
+		Version:  in.Version,
 	}
 }
 
@@ -52,7 +71,7 @@ type UpdateProgramURI struct {
 type UpdateProgramJSON struct {
 	Name     string   `json:"name"      binding:"required"`
 	SharedBy string   `json:"shared_by" binding:"required"`
-	Days     []db.Day `json:"days"`
+	Days     []db.Day `json:"days"      binding:"dive"`
 }
 
 // Merge combines them into your full DTO
@@ -64,3 +83,101 @@ func (u UpdateProgramURI) Merge(j UpdateProgramJSON) UpdateProgramInput {
 		Days:     j.Days,
 	}
 }
+
+// PatchProgramJSON only for JSON binding; absent fields are left unchanged.
+type PatchProgramJSON struct {
+	Name     *string   `json:"name"      binding:"omitempty,min=1"`
+	SharedBy *string   `json:"shared_by" binding:"omitempty,min=1"`
+	Days     *[]db.Day `json:"days"      binding:"omitempty,dive"`
+}
+
+// PatchProgramInput merges ID+body+If-Match for PATCH /programs/:id.
+type PatchProgramInput struct {
+	ID      string
+	Patch   PatchProgramJSON
+	Version int
+}
+
+// Apply writes the present fields onto p.
+func (j PatchProgramJSON) Apply(p *db.Program) {
+	if j.Name != nil {
+		p.Name = *j.Name
+	}
+	if j.SharedBy != nil {
+		p.SharedBy = *j.SharedBy
+	}
+	if j.Days != nil {
+		p.Days = *j.Days
+	}
+}
+
+// DeleteProgramInput holds the :id param and If-Match version for
+// DELETE /programs/:id.
+type DeleteProgramInput struct {
+	ID      string
+	Version int
+}
+
+// RestoreProgramInput holds the :id param for POST /programs/:id/restore.
+type RestoreProgramInput struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// ForkProgramURI only for URI binding
+type ForkProgramURI struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// ForkProgramJSON only for JSON binding
+type ForkProgramJSON struct {
+	SharedBy string `json:"shared_by" binding:"required"`
+}
+
+// ForkProgramInput merges ID+body for POST /programs/:id/fork.
+type ForkProgramInput struct {
+	ID       string
+	SharedBy string
+}
+
+// Merge combines them into your full DTO
+func (u ForkProgramURI) Merge(j ForkProgramJSON) ForkProgramInput {
+	return ForkProgramInput{ID: u.ID, SharedBy: j.SharedBy}
+}
+
+// DayURI holds the params for the /programs/:id/days/:dayId endpoints.
+type DayURI struct {
+	ID    string `uri:"id" binding:"required"`
+	DayID string `uri:"dayId" binding:"required"`
+}
+
+// WarmupQuery picks the ramp template and, like GetProgramQuery, whose
+// training maxes resolve percentage loads and which unit to answer in.
+// With InventoryID, loads are rounded to what that inventory can make, in
+// its unit.
+type WarmupQuery struct {
+	Template    string `form:"template"`
+	UserID      string `form:"user_id"`
+	Unit        string `form:"unit" binding:"omitempty,oneof=kg lb"`
+	InventoryID string `form:"inventory_id"`
+}
+
+// WarmupInput merges URI+query for GET /programs/:id/days/:dayId/warmup.
+type WarmupInput struct {
+	DayURI
+	WarmupQuery
+}
+
+// SessionQuery picks the plate inventory for GET
+// /programs/:id/days/:dayId/session, defaulting to a standard one in Unit
+// (kg unless given), and whose training maxes resolve percentage loads.
+type SessionQuery struct {
+	UserID      string `form:"user_id"`
+	InventoryID string `form:"inventory_id"`
+	Unit        string `form:"unit" binding:"omitempty,oneof=kg lb"`
+}
+
+// SessionInput merges URI+query for GET /programs/:id/days/:dayId/session.
+type SessionInput struct {
+	DayURI
+	SessionQuery
+}
@@ -16,15 +16,20 @@ import (
 
 type mockRepo struct {
 	CreateFn func(ctx context.Context, p *db.Program) (*db.Program, error)
+	ManyFn   func(ctx context.Context, ps []*db.Program) ([]*db.Program, error)
 	GetFn    func(ctx context.Context, id string) (*db.Program, error)
 	ListFn   func(ctx context.Context) ([]db.Program, error)
 	UpdateFn func(ctx context.Context, p *db.Program) (*db.Program, error)
-	DeleteFn func(ctx context.Context, id string) error
+	DeleteFn func(ctx context.Context, id string, version int) error
+	ForkFn   func(ctx context.Context, id string, sharedBy string) (*db.Program, error)
 }
 
 func (m *mockRepo) Create(ctx context.Context, p *db.Program) (*db.Program, error) {
 	return m.CreateFn(ctx, p)
 }
+func (m *mockRepo) CreateMany(ctx context.Context, ps []*db.Program) ([]*db.Program, error) {
+	return m.ManyFn(ctx, ps)
+}
 func (m *mockRepo) Get(ctx context.Context, id string) (*db.Program, error) {
 	return m.GetFn(ctx, id)
 }
@@ -32,7 +37,12 @@ func (m *mockRepo) List(ctx context.Context) ([]db.Program, error) { return m.Li
 func (m *mockRepo) Update(ctx context.Context, p *db.Program) (*db.Program, error) {
 	return m.UpdateFn(ctx, p)
 }
-func (m *mockRepo) Delete(ctx context.Context, id string) error { return m.DeleteFn(ctx, id) }
+func (m *mockRepo) Delete(ctx context.Context, id string, version int) error {
+	return m.DeleteFn(ctx, id, version)
+}
+func (m *mockRepo) Fork(ctx context.Context, id string, sharedBy string) (*db.Program, error) {
+	return m.ForkFn(ctx, id, sharedBy)
+}
 
 func TestCreateProgram_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -265,7 +275,7 @@ func TestDeleteProgram_Success(t *testing.T) {
 
 	called := false
 	repo := &mockRepo{
-		DeleteFn: func(ctx context.Context, id string) error {
+		DeleteFn: func(ctx context.Context, id string, version int) error {
 			called = true
 			assert.Equal(t, "uuid-789", id)
 			assert.NotNil(t, ctx)
@@ -290,7 +300,7 @@ func TestDeleteProgram_Error(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	repo := &mockRepo{
-		DeleteFn: func(ctx context.Context, id string) error {
+		DeleteFn: func(ctx context.Context, id string, version int) error {
 			return errors.New("delete failed")
 		},
 	}
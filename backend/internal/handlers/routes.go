@@ -3,12 +3,63 @@ package handlers
 import "github.com/gin-gonic/gin"
 
 func (h *Handler) RegisterRoutes(r *gin.Engine) {
-	api := r.Group("/api/v1")
+	api := r.Group(apiPrefix, h.Middleware...)
 	{
 		api.POST("/programs", h.CreateProgram)
+		api.POST("/programs/batch", h.BatchCreatePrograms)
+		api.POST("/programs/import", h.ImportProgram)
 		api.GET("/programs", h.ListPrograms)
 		api.GET("/programs/:id", h.GetProgram)
 		api.PUT("/programs/:id", h.UpdateProgram)
+		api.PATCH("/programs/:id", h.PatchProgram)
 		api.DELETE("/programs/:id", h.DeleteProgram)
+		api.POST("/programs/:id/fork", h.ForkProgram)
+		api.GET("/programs/:id/stats", h.ProgramStats)
+		api.GET("/programs/:id/print", h.PrintProgram)
+		api.GET("/programs/:id/days/:dayId/warmup", h.DayWarmup)
+		api.GET("/programs/:id/days/:dayId/session", h.DaySession)
+		api.POST("/plates/calculate", h.CalculatePlates)
+		api.GET("/openapi.json", h.OpenAPISpec(r))
+		api.GET("/docs", h.APIDocs)
+	}
+
+	if h.Trash != nil {
+		api.GET("/programs/trash", h.ListTrash)
+		api.POST("/programs/:id/restore", h.RestoreProgram)
+	}
+
+	if h.Search != nil {
+		api.GET("/search", h.SearchPrograms)
+	}
+
+	if h.TrainingMaxes != nil {
+		api.GET("/training-maxes", h.ListTrainingMaxes)
+		api.PUT("/training-maxes", h.PutTrainingMax)
+		api.DELETE("/training-maxes/:id", h.DeleteTrainingMax)
+	}
+
+	if h.Plates != nil {
+		api.POST("/plate-inventories", h.CreatePlateInventory)
+		api.GET("/plate-inventories", h.ListPlateInventories)
+		api.DELETE("/plate-inventories/:id", h.DeletePlateInventory)
+	}
+
+	if h.Templates != nil {
+		api.GET("/templates", h.ListTemplates)
+		api.POST("/templates/:slug/instantiate", h.InstantiateTemplate)
+	}
+
+	if h.Export != nil {
+		api.GET("/programs/export.ndjson", h.ExportPrograms)
+	}
+
+	if h.CacheStats != nil {
+		api.GET("/cache/stats", h.GetCacheStats)
+	}
+
+	if h.Webhooks != nil {
+		api.POST("/webhooks", h.CreateWebhook)
+		api.GET("/webhooks", h.ListWebhooks)
+		api.DELETE("/webhooks/:id", h.DeleteWebhook)
 	}
 }
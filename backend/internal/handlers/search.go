@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+)
+
+// SearchPrograms handles GET /api/v1/search?q=...
+func (h *Handler) SearchPrograms(c *gin.Context) {
+	HandleJSON[SearchInput, []repos.SearchHit](
+		c,
+		BindQuery[SearchInput],
+		func(ctx context.Context, in SearchInput) ([]repos.SearchHit, error) {
+			limit := in.Limit
+			if limit == 0 {
+				limit = defaultSearchLimit
+			}
+			return h.Search.Search(ctx, in.Q, limit)
+		},
+		http.StatusOK,
+	)
+}
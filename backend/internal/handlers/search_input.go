@@ -0,0 +1,10 @@
+package handlers
+
+// defaultSearchLimit caps results when ?limit is omitted.
+const defaultSearchLimit = 20
+
+// SearchInput maps the query string for GET /search.
+type SearchInput struct {
+	Q     string `form:"q" binding:"required,min=1,max=200"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/internal/stats"
+)
+
+// ProgramStats handles GET /api/v1/programs/:id/stats
+func (h *Handler) ProgramStats(c *gin.Context) {
+	HandleJSON[GetProgramInput, stats.ProgramStats](
+		c,
+		BindURI[GetProgramInput],
+		func(ctx context.Context, in GetProgramInput) (stats.ProgramStats, error) {
+			p, err := h.Repo.Get(ctx, in.ID)
+			if err != nil {
+				return stats.ProgramStats{}, err
+			}
+			return stats.Compute(p), nil
+		},
+		http.StatusOK,
+	)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/templates"
+)
+
+// ListTemplates handles GET /api/v1/templates
+func (h *Handler) ListTemplates(c *gin.Context) {
+	HandleJSON[ListProgramsInput, []templates.Summary](
+		c,
+		func(c *gin.Context) (ListProgramsInput, error) {
+			return ListProgramsInput{}, nil
+		},
+		func(ctx context.Context, _ ListProgramsInput) ([]templates.Summary, error) {
+			return h.Templates.List(), nil
+		},
+		http.StatusOK,
+	)
+}
+
+// InstantiateTemplate handles POST /api/v1/templates/:slug/instantiate. The
+// program is created like any other, so it can be edited, forked and
+// shared afterwards.
+func (h *Handler) InstantiateTemplate(c *gin.Context) {
+	HandleJSON[InstantiateTemplateInput, *db.Program](
+		c,
+		func(c *gin.Context) (InstantiateTemplateInput, error) {
+			uri, err := BindURI[TemplateURI](c)
+			if err != nil {
+				return InstantiateTemplateInput{}, err
+			}
+			body, err := BindJSON[InstantiateTemplateJSON](c)
+			return uri.Merge(body), err
+		},
+		func(ctx context.Context, in InstantiateTemplateInput) (*db.Program, error) {
+			t, ok := h.Templates.Get(in.Slug)
+			if !ok {
+				return nil, &StatusError{http.StatusNotFound, fmt.Errorf("no template %q", in.Slug)}
+			}
+
+			params := templates.Params{Name: in.Name, SharedBy: in.SharedBy, Days: in.Days}
+			if len(t.Lifts()) > 0 {
+				if in.UserID == "" || h.TrainingMaxes == nil {
+					return nil, &StatusError{http.StatusUnprocessableEntity, fmt.Errorf(
+						"%s is based on training maxes; give the user_id they are stored under", t.Name)}
+				}
+				maxes, err := h.TrainingMaxes.List(ctx, in.UserID)
+				if err != nil {
+					return nil, err
+				}
+				params.Maxes = maxes
+			}
+
+			p, err := t.Instantiate(params)
+			var missing *templates.MissingMaxesError
+			switch {
+			case errors.As(err, &missing):
+				return nil, &StatusError{http.StatusUnprocessableEntity, err}
+			case err != nil:
+				return nil, &StatusError{http.StatusBadRequest, err}
+			}
+			if err := h.checkSize(p.Days); err != nil {
+				return nil, err
+			}
+			return h.Repo.Create(ctx, p)
+		},
+		http.StatusCreated,
+	)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+// TemplateURI holds the :slug param for /templates/:slug routes.
+type TemplateURI struct {
+	Slug string `uri:"slug" binding:"required"`
+}
+
+// InstantiateTemplateJSON maps the body of POST /templates/:slug/instantiate.
+// UserID names whose training maxes fill in the template's percentages;
+// Days are the weekdays to train on, the template's defaults when empty.
+type InstantiateTemplateJSON struct {
+	SharedBy string   `json:"shared_by" binding:"required"`
+	Name     string   `json:"name"`
+	UserID   string   `json:"user_id"`
+	Days     []string `json:"days"`
+}
+
+// InstantiateTemplateInput merges slug+body.
+type InstantiateTemplateInput struct {
+	Slug string
+	InstantiateTemplateJSON
+}
+
+func (u TemplateURI) Merge(j InstantiateTemplateJSON) InstantiateTemplateInput {
+	return InstantiateTemplateInput{Slug: u.Slug, InstantiateTemplateJSON: j}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// ListTrainingMaxes handles GET /api/v1/training-maxes?user_id=...
+func (h *Handler) ListTrainingMaxes(c *gin.Context) {
+	HandleJSON[ListTrainingMaxesInput, []db.TrainingMax](
+		c,
+		BindQuery[ListTrainingMaxesInput],
+		func(ctx context.Context, in ListTrainingMaxesInput) ([]db.TrainingMax, error) {
+			return h.TrainingMaxes.List(ctx, in.UserID)
+		},
+		http.StatusOK,
+	)
+}
+
+// PutTrainingMax handles PUT /api/v1/training-maxes
+func (h *Handler) PutTrainingMax(c *gin.Context) {
+	HandleJSON[PutTrainingMaxInput, *db.TrainingMax](
+		c,
+		BindJSON[PutTrainingMaxInput],
+		func(ctx context.Context, in PutTrainingMaxInput) (*db.TrainingMax, error) {
+			return h.TrainingMaxes.Upsert(ctx, in.ToModel())
+		},
+		http.StatusOK,
+	)
+}
+
+// DeleteTrainingMax handles DELETE /api/v1/training-maxes/:id
+func (h *Handler) DeleteTrainingMax(c *gin.Context) {
+	HandleJSON[DeleteTrainingMaxInput, struct{}](
+		c,
+		BindURI[DeleteTrainingMaxInput],
+		func(ctx context.Context, in DeleteTrainingMaxInput) (struct{}, error) {
+			return struct{}{}, h.TrainingMaxes.Delete(ctx, in.ID)
+		},
+		http.StatusNoContent,
+	)
+}
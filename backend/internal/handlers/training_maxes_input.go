@@ -0,0 +1,31 @@
+package handlers
+
+import "github.com/iraunchy/dyel/backend/db"
+
+// ListTrainingMaxesInput maps the query for GET /training-maxes.
+type ListTrainingMaxesInput struct {
+	UserID string `form:"user_id" binding:"required"`
+}
+
+// PutTrainingMaxInput maps the JSON body for PUT /training-maxes.
+type PutTrainingMaxInput struct {
+	UserID   string  `json:"user_id" binding:"required"`
+	Exercise string  `json:"exercise" binding:"required"`
+	Value    float64 `json:"value" binding:"required,gt=0,lte=1000"`
+	Unit     string  `json:"unit" binding:"required,oneof=kg lb"`
+}
+
+// ToModel converts PutTrainingMaxInput → *db.TrainingMax
+func (in PutTrainingMaxInput) ToModel() *db.TrainingMax {
+	return &db.TrainingMax{
+		UserID:   in.UserID,
+		Exercise: in.Exercise,
+		Value:    in.Value,
+		Unit:     in.Unit,
+	}
+}
+
+// DeleteTrainingMaxInput holds the :id param for DELETE /training-maxes/:id.
+type DeleteTrainingMaxInput struct {
+	ID string `uri:"id" binding:"required"`
+}
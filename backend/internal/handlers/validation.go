@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// Struct-level rules gin runs whenever a bound input dives into them.
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterStructValidation(validateDay, db.Day{})
+	v.RegisterStructValidation(validateIntensity, db.Intensity{})
+}
+
+// validateDay enforces the cross-field rules of db.Day.ValidateGroups.
+func validateDay(sl validator.StructLevel) {
+	day := sl.Current().Interface().(db.Day)
+	if err := day.ValidateGroups(); err != nil {
+		sl.ReportError(day.Groups, "Groups", "groups", "groups", err.Error())
+	}
+}
+
+// validateIntensity enforces db.Intensity.Validate.
+func validateIntensity(sl validator.StructLevel) {
+	in := sl.Current().Interface().(db.Intensity)
+	if err := in.Validate(); err != nil {
+		sl.ReportError(in, "Intensity", "intensity", "intensity", err.Error())
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/internal/warmup"
+)
+
+// DayWarmup handles GET /api/v1/programs/:id/days/:dayId/warmup
+func (h *Handler) DayWarmup(c *gin.Context) {
+	HandleJSON[WarmupInput, warmup.DayPlan](
+		c,
+		func(c *gin.Context) (WarmupInput, error) {
+			uri, err := BindURI[DayURI](c)
+			if err != nil {
+				return WarmupInput{}, err
+			}
+			q, err := BindQuery[WarmupQuery](c)
+			return WarmupInput{DayURI: uri, WarmupQuery: q}, err
+		},
+		func(ctx context.Context, in WarmupInput) (warmup.DayPlan, error) {
+			templates := h.WarmupTemplates
+			if templates == nil {
+				templates = warmup.Defaults
+			}
+			name := in.Template
+			if name == "" {
+				name = warmup.DefaultTemplate
+			}
+			t, ok := templates[name]
+			if !ok {
+				return warmup.DayPlan{}, &StatusError{http.StatusBadRequest, fmt.Errorf(
+					"unknown warm-up template %q; have %s", name, strings.Join(warmup.Names(templates), ", "))}
+			}
+
+			unit, rounding := in.Unit, warmup.DefaultRounding
+			if in.InventoryID != "" {
+				inv, err := h.inventory(ctx, in.InventoryID, "")
+				if err != nil {
+					return warmup.DayPlan{}, err
+				}
+				unit, rounding = inv.Unit, map[string]warmup.Rounding{inv.Unit: warmup.InventoryRounding(inv)}
+			}
+
+			d, err := h.loadDay(ctx, in.DayURI, in.UserID)
+			if err != nil {
+				return warmup.DayPlan{}, err
+			}
+			return warmup.Plan(d, t, unit, rounding), nil
+		},
+		http.StatusOK,
+	)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/webhooks"
+)
+
+// CreateWebhook handles POST /api/v1/webhooks
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	HandleJSON[CreateWebhookInput, CreatedWebhook](
+		c,
+		BindJSON[CreateWebhookInput],
+		func(ctx context.Context, in CreateWebhookInput) (CreatedWebhook, error) {
+			if !h.PrivateWebhooks {
+				if err := webhooks.CheckURL(ctx, in.URL); err != nil {
+					return CreatedWebhook{}, &StatusError{http.StatusUnprocessableEntity, err}
+				}
+			}
+			ep, err := h.Webhooks.Create(ctx, in.ToModel())
+			if err != nil {
+				return CreatedWebhook{}, err
+			}
+			return CreatedWebhook{WebhookEndpoint: ep, Secret: ep.Secret}, nil
+		},
+		http.StatusCreated,
+	)
+}
+
+// ListWebhooks handles GET /api/v1/webhooks
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	HandleJSON[struct{}, []db.WebhookEndpoint](
+		c,
+		func(c *gin.Context) (struct{}, error) {
+			return struct{}{}, nil
+		},
+		func(ctx context.Context, _ struct{}) ([]db.WebhookEndpoint, error) {
+			return h.Webhooks.List(ctx)
+		},
+		http.StatusOK,
+	)
+}
+
+// DeleteWebhook handles DELETE /api/v1/webhooks/:id
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	HandleJSON[DeleteWebhookInput, struct{}](
+		c,
+		BindURI[DeleteWebhookInput],
+		func(ctx context.Context, in DeleteWebhookInput) (struct{}, error) {
+			return struct{}{}, h.Webhooks.Delete(ctx, in.ID)
+		},
+		http.StatusNoContent,
+	)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// CreateWebhookInput maps the JSON body for POST /webhooks.
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events" binding:"dive,oneof=program.created program.updated program.deleted program.forked program.restored *"`
+}
+
+// ToModel converts CreateWebhookInput → *db.WebhookEndpoint
+func (in CreateWebhookInput) ToModel() *db.WebhookEndpoint {
+	return &db.WebhookEndpoint{
+		URL:    in.URL,
+		Secret: in.Secret,
+		Events: strings.Join(in.Events, ","),
+	}
+}
+
+// CreatedWebhook is the POST /webhooks response, the only place the signing
+// secret is ever returned.
+type CreatedWebhook struct {
+	*db.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// DeleteWebhookInput holds the :id param for DELETE /webhooks/:id.
+type DeleteWebhookInput struct {
+	ID string `uri:"id" binding:"required"`
+}
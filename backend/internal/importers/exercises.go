@@ -0,0 +1,126 @@
+package importers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// catalog is the exercise names programs use, as the seed data and the
+// template library spell them.
+var catalog = []string{
+	"Back Squat", "Front Squat", "Goblet Squat", "Hack Squat", "Leg Press",
+	"Bulgarian Split Squat", "Walking Lunge", "Step-Up",
+	"Deadlift", "Sumo Deadlift", "Romanian Deadlift", "Trap Bar Deadlift",
+	"Hip Thrust", "Power Clean",
+	"Bench Press", "Incline Bench Press", "Close-Grip Bench Press",
+	"Dumbbell Bench Press", "Incline Dumbbell Bench Press", "Dips", "Push-Up",
+	"Overhead Press", "Push Press", "Seated Dumbbell Press",
+	"Barbell Row", "Pendlay Row", "Dumbbell Row", "Seated Cable Row",
+	"Chest-Supported Row", "T-Bar Row", "Pull-Up", "Chin-Up", "Lat Pulldown",
+	"Face Pull", "Lateral Raise", "Cable Lateral Raise", "Rear Delt Fly",
+	"Reverse Pec Deck", "Shrug",
+	"Cable Fly", "Dumbbell Fly", "Pec Deck",
+	"Triceps Pushdown", "Skull Crusher", "Overhead Triceps Extension",
+	"Barbell Curl", "Dumbbell Curl", "Hammer Curl", "Incline Dumbbell Curl",
+	"Cable Curl", "Preacher Curl",
+	"Leg Extension", "Lying Leg Curl", "Seated Leg Curl", "Nordic Curl",
+	"Glute-Ham Raise", "Standing Calf Raise", "Seated Calf Raise",
+	"Plank", "Hanging Leg Raise", "Ab Wheel Rollout", "Cable Crunch", "Pallof Press",
+}
+
+// aliases are other apps' names for catalog exercises, normalised, with
+// any equipment moved to the front as "dumbbell bench press".
+var aliases = map[string]string{
+	"squat":                          "Back Squat",
+	"barbell squat":                  "Back Squat",
+	"ohp":                            "Overhead Press",
+	"military press":                 "Overhead Press",
+	"shoulder press":                 "Overhead Press",
+	"dumbbell shoulder press":        "Seated Dumbbell Press",
+	"rdl":                            "Romanian Deadlift",
+	"bent over row":                  "Barbell Row",
+	"dumbbell bent over row":         "Dumbbell Row",
+	"dumbbell incline bench press":   "Incline Dumbbell Bench Press",
+	"dumbbell bicep curl":            "Dumbbell Curl",
+	"dumbbell biceps curl":           "Dumbbell Curl",
+	"cable bicep curl":               "Cable Curl",
+	"cable biceps curl":              "Cable Curl",
+	"bicep curl":                     "Barbell Curl",
+	"biceps curl":                    "Barbell Curl",
+	"dumbbell hammer curl":           "Hammer Curl",
+	"skullcrusher":                   "Skull Crusher",
+	"dip":                            "Dips",
+	"triceps dip":                    "Dips",
+	"chest dip":                      "Dips",
+	"triceps extension":              "Overhead Triceps Extension",
+	"seated row":                     "Seated Cable Row",
+	"dumbbell chest fly":             "Dumbbell Fly",
+	"cable chest fly":                "Cable Fly",
+	"machine chest fly":              "Pec Deck",
+	"butterfly":                      "Pec Deck",
+	"cable lateral raise":            "Cable Lateral Raise",
+	"reverse fly":                    "Rear Delt Fly",
+	"lunge":                          "Walking Lunge",
+	"calf raise":                     "Standing Calf Raise",
+	"ab wheel":                       "Ab Wheel Rollout",
+	"leg curl":                       "Lying Leg Curl",
+	"glute ham raise":                "Glute-Ham Raise",
+	"barbell hip thrust":             "Hip Thrust",
+	"barbell bench press":            "Bench Press",
+	"barbell incline bench press":    "Incline Bench Press",
+	"barbell overhead press":         "Overhead Press",
+	"close grip bench press":         "Close-Grip Bench Press",
+	"barbell close grip bench press": "Close-Grip Bench Press",
+}
+
+// barbellLifts are the catalog exercises a name only matches in its
+// barbell form: "Bench Press (Dumbbell)" is not a Bench Press.
+var barbellLifts = map[string]bool{
+	"Back Squat": true, "Front Squat": true, "Deadlift": true, "Sumo Deadlift": true,
+	"Bench Press": true, "Incline Bench Press": true, "Close-Grip Bench Press": true,
+	"Overhead Press": true, "Push Press": true, "Barbell Row": true, "Pendlay Row": true,
+	"Barbell Curl": true, "Power Clean": true, "Hip Thrust": true,
+}
+
+var known = func() map[string]string {
+	m := make(map[string]string, len(catalog)+len(aliases))
+	for _, name := range catalog {
+		m[normalise(name)] = name
+	}
+	for alias, name := range aliases {
+		m[alias] = name
+	}
+	return m
+}()
+
+// equipment splits "Lat Pulldown (Cable - Wide Grip)" into its movement
+// and equipment, as Strong and Hevy name exercises.
+var equipment = regexp.MustCompile(`^(.*?)\s*\(([^)]*)\)$`)
+
+// MapExercise finds the catalog name for an exercise name from another
+// app, such as Back Squat for "Squat (Barbell)". It reports false when
+// there is none.
+func MapExercise(name string) (string, bool) {
+	base, equip := name, ""
+	if m := equipment.FindStringSubmatch(strings.TrimSpace(name)); m != nil {
+		base, equip = m[1], normalise(strings.SplitN(m[2], " - ", 2)[0])
+	}
+	base = normalise(base)
+	if equip != "" {
+		if c, ok := known[equip+" "+base]; ok {
+			return c, true
+		}
+	}
+	c, ok := known[base]
+	if !ok || (equip != "" && equip != "barbell" && barbellLifts[c]) {
+		return "", false
+	}
+	return c, true
+}
+
+// normalise lower-cases name and treats hyphens as spaces, so "Pull-Up",
+// "Pull Up" and "pull up" are one exercise.
+func normalise(name string) string {
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
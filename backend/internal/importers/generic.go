@@ -0,0 +1,128 @@
+package importers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// GenericColumns are the columns of the generic spreadsheet layout, one
+// row per exercise. Column names are matched in any case and order; only
+// day, exercise, sets and reps are required.
+//
+//	day         the day the exercise belongs to; days keep the order in
+//	            which they first appear
+//	exercise    the exercise name
+//	sets        the number of sets
+//	reps        reps per set, such as 5, 8-12 or AMRAP
+//	rest        rest between sets, such as 90s or 3m
+//	load        the weight to use
+//	unit        kg or lb, for load; the import's unit when empty
+//	percent_tm  a percentage of the training max instead of load
+//	rpe         target RPE
+//	rir         target reps in reserve instead of rpe
+//	tempo       tempo, such as 3-1-1-0
+var GenericColumns = []string{"day", "exercise", "sets", "reps", "rest", "load", "unit", "percent_tm", "rpe", "rir", "tempo"}
+
+// generic reads a program laid out in GenericColumns.
+func generic(t *table, opts Options) (*Result, error) {
+	if err := t.require(GenericColumns[:4]...); err != nil {
+		return nil, err
+	}
+	res := &Result{Program: &db.Program{}}
+	var order []string
+	days := map[string]*db.Day{}
+	unmapped := map[string]bool{}
+	for i := range t.rows {
+		name := t.get(i, "day")
+		if name == "" && t.get(i, "exercise") == "" {
+			continue
+		}
+		if name == "" {
+			return nil, t.rowError(i, fmt.Errorf("day is empty"))
+		}
+		ex, err := genericExercise(t, i, opts.Unit)
+		if err != nil {
+			return nil, t.rowError(i, err)
+		}
+		if canonical, known := MapExercise(ex.Name); known {
+			ex.Name = canonical
+		} else {
+			unmapped[ex.Name] = true
+		}
+		if days[name] == nil {
+			days[name] = &db.Day{Name: name}
+			order = append(order, name)
+		}
+		days[name].Exercises = append(days[name].Exercises, ex)
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no exercises in the file")
+	}
+	for _, name := range order {
+		res.Program.Days = append(res.Program.Days, *days[name])
+	}
+	res.Unmapped = sortedKeys(unmapped)
+	return res, nil
+}
+
+func genericExercise(t *table, i int, unit string) (db.Exercise, error) {
+	ex := db.Exercise{
+		Name: t.get(i, "exercise"),
+		Reps: t.get(i, "reps"),
+		Rest: t.get(i, "rest"),
+	}
+	ex.Tempo = t.get(i, "tempo")
+	if ex.Name == "" || ex.Reps == "" {
+		return ex, fmt.Errorf("exercise and reps are required")
+	}
+	sets, err := strconv.Atoi(t.get(i, "sets"))
+	if err != nil || sets < 1 {
+		return ex, fmt.Errorf("sets %q is not a positive whole number", t.get(i, "sets"))
+	}
+	ex.Sets = sets
+
+	if ex.Load, err = optional(t.get(i, "load"), "load"); err != nil {
+		return ex, err
+	}
+	if ex.Load != nil {
+		ex.LoadUnit = unit
+		switch u := strings.ToLower(t.get(i, "unit")); u {
+		case "":
+		case "kg", "kgs":
+			ex.LoadUnit = db.UnitKg
+		case "lb", "lbs":
+			ex.LoadUnit = db.UnitLb
+		default:
+			return ex, fmt.Errorf("unit %q is not kg or lb", u)
+		}
+	}
+	if ex.PercentTM, err = optional(strings.TrimSuffix(t.get(i, "percent_tm"), "%"), "percent_tm"); err != nil {
+		return ex, err
+	}
+	if ex.RPE, err = optional(t.get(i, "rpe"), "rpe"); err != nil {
+		return ex, err
+	}
+	if s := t.get(i, "rir"); s != "" {
+		rir, err := strconv.Atoi(s)
+		if err != nil {
+			return ex, fmt.Errorf("rir %q is not a whole number", s)
+		}
+		ex.RIR = &rir
+	}
+	return ex, ex.Intensity.Validate()
+}
+
+// optional parses a number cell that may be empty.
+func optional(s, col string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := number(s, col)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
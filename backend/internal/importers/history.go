@@ -0,0 +1,273 @@
+package importers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// loggedSet is one set of a workout history.
+type loggedSet struct {
+	workout  string
+	start    time.Time
+	exercise string
+	warmup   bool
+	// weight is zero for bodyweight sets.
+	weight float64
+	unit   string
+	reps   int
+	rpe    float64
+}
+
+// history turns a reader of logged sets into a reader of programs.
+func history(read func(*table, string) ([]loggedSet, error)) func(*table, Options) (*Result, error) {
+	return func(t *table, opts Options) (*Result, error) {
+		sets, err := read(t, opts.Unit)
+		if err != nil {
+			return nil, err
+		}
+		if len(sets) == 0 {
+			return nil, fmt.Errorf("no sets in the file")
+		}
+		return fromHistory(sets, opts.Window), nil
+	}
+}
+
+// fromHistory makes the current routine of a history into a program: a
+// day for every workout last done within window of the most recent one,
+// in the order they were last done, each with its exercises as they were
+// done that time.
+func fromHistory(sets []loggedSet, window time.Duration) *Result {
+	type session struct {
+		workout string
+		start   time.Time
+	}
+	sessions := map[session]bool{}
+	latest := map[string]time.Time{}
+	var last time.Time
+	for _, s := range sets {
+		sessions[session{s.workout, s.start}] = true
+		if s.start.After(latest[s.workout]) {
+			latest[s.workout] = s.start
+		}
+		if s.start.After(last) {
+			last = s.start
+		}
+	}
+
+	var routine []string
+	for w, at := range latest {
+		if !at.Before(last.Add(-window)) {
+			routine = append(routine, w)
+		}
+	}
+	slices.SortFunc(routine, func(a, b string) int {
+		if c := latest[a].Compare(latest[b]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	res := &Result{Program: &db.Program{}, Workouts: len(sessions)}
+	unmapped := map[string]bool{}
+	for _, w := range routine {
+		var done []loggedSet
+		for _, s := range sets {
+			if s.workout == w && s.start.Equal(latest[w]) {
+				done = append(done, s)
+			}
+		}
+		day := db.Day{Name: w}
+		if day.Name == "" {
+			day.Name = "Workout"
+		}
+		for _, name := range exerciseOrder(done) {
+			ex, ok := prescribe(name, done)
+			if !ok {
+				continue
+			}
+			if canonical, known := MapExercise(name); known {
+				ex.Name = canonical
+			} else {
+				unmapped[name] = true
+			}
+			day.Exercises = append(day.Exercises, ex)
+		}
+		if len(day.Exercises) > 0 {
+			res.Program.Days = append(res.Program.Days, day)
+		}
+	}
+	res.Unmapped = sortedKeys(unmapped)
+	return res
+}
+
+func exerciseOrder(sets []loggedSet) []string {
+	var names []string
+	for _, s := range sets {
+		if !slices.Contains(names, s.exercise) {
+			names = append(names, s.exercise)
+		}
+	}
+	return names
+}
+
+// prescribe sums up the working sets of one exercise in a session. It
+// reports false when there were none, as for warm-up only or cardio
+// entries.
+func prescribe(name string, sets []loggedSet) (db.Exercise, bool) {
+	ex := db.Exercise{Name: name}
+	lo, hi := 0, 0
+	var top, rpe float64
+	var unit string
+	for _, s := range sets {
+		if s.exercise != name || s.warmup || s.reps == 0 {
+			continue
+		}
+		ex.Sets++
+		if lo == 0 || s.reps < lo {
+			lo = s.reps
+		}
+		hi = max(hi, s.reps)
+		if s.weight > top {
+			top, unit = s.weight, s.unit
+		}
+		rpe = max(rpe, s.rpe)
+	}
+	if ex.Sets == 0 {
+		return ex, false
+	}
+	ex.Reps = strconv.Itoa(lo)
+	if hi > lo {
+		ex.Reps += "-" + strconv.Itoa(hi)
+	}
+	if top > 0 {
+		ex.Load, ex.LoadUnit = &top, unit
+	}
+	if rpe >= 1 && rpe <= 10 {
+		ex.RPE = &rpe
+	}
+	return ex, true
+}
+
+func sortedKeys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// strong reads the CSV Strong exports from Settings → Export Data. Its
+// weights are in the app's unit, which the file only names in exports
+// from older versions.
+func strong(t *table, unit string) ([]loggedSet, error) {
+	if err := t.require("date", "workout name", "exercise name", "set order", "weight", "reps"); err != nil {
+		return nil, err
+	}
+	var out []loggedSet
+	for i := range t.rows {
+		s, err := strongSet(t, i, unit)
+		if err != nil {
+			return nil, t.rowError(i, err)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func strongSet(t *table, i int, unit string) (loggedSet, error) {
+	s := loggedSet{
+		workout:  t.get(i, "workout name"),
+		exercise: t.get(i, "exercise name"),
+		unit:     unit,
+	}
+	if s.exercise == "" {
+		return s, fmt.Errorf("exercise name is empty")
+	}
+	var err error
+	if s.start, err = timestamp(t.get(i, "date"), "date", "2006-01-02 15:04:05", "2006-01-02 15:04", time.RFC3339); err != nil {
+		return s, err
+	}
+	// Set Order numbers working sets; warm-ups are W, drop sets D and
+	// failure sets F.
+	s.warmup = strings.EqualFold(t.get(i, "set order"), "W")
+	if s.weight, err = number(t.get(i, "weight"), "weight"); err != nil {
+		return s, err
+	}
+	reps, err := number(t.get(i, "reps"), "reps")
+	if err != nil {
+		return s, err
+	}
+	s.reps = int(reps)
+	if s.rpe, err = number(t.get(i, "rpe"), "RPE"); err != nil {
+		return s, err
+	}
+	switch u := strings.ToLower(t.get(i, "weight unit")); u {
+	case "":
+	case "kg", "kgs":
+		s.unit = db.UnitKg
+	case "lb", "lbs":
+		s.unit = db.UnitLb
+	default:
+		return s, fmt.Errorf("weight unit %q is not kg or lbs", u)
+	}
+	return s, nil
+}
+
+// hevy reads the CSV Hevy exports from Settings → Export & Import Data.
+// Weights are in weight_kg, or weight_lbs in exports made in pounds.
+func hevy(t *table, _ string) ([]loggedSet, error) {
+	if err := t.require("title", "start_time", "exercise_title", "set_type", "reps"); err != nil {
+		return nil, err
+	}
+	weightCol, unit := "weight_kg", db.UnitKg
+	if !t.has(weightCol) {
+		weightCol, unit = "weight_lbs", db.UnitLb
+	}
+	if !t.has(weightCol) {
+		return nil, fmt.Errorf("missing columns: weight_kg or weight_lbs")
+	}
+	var out []loggedSet
+	for i := range t.rows {
+		s, err := hevySet(t, i, weightCol, unit)
+		if err != nil {
+			return nil, t.rowError(i, err)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func hevySet(t *table, i int, weightCol, unit string) (loggedSet, error) {
+	s := loggedSet{
+		workout:  t.get(i, "title"),
+		exercise: t.get(i, "exercise_title"),
+		unit:     unit,
+		// The other set types are normal, failure and dropset.
+		warmup: t.get(i, "set_type") == "warmup",
+	}
+	if s.exercise == "" {
+		return s, fmt.Errorf("exercise_title is empty")
+	}
+	var err error
+	if s.start, err = timestamp(t.get(i, "start_time"), "start_time", "2 Jan 2006, 15:04", "2006-01-02 15:04:05", time.RFC3339); err != nil {
+		return s, err
+	}
+	if s.weight, err = number(t.get(i, weightCol), weightCol); err != nil {
+		return s, err
+	}
+	reps, err := number(t.get(i, "reps"), "reps")
+	if err != nil {
+		return s, err
+	}
+	s.reps = int(reps)
+	if s.rpe, err = number(t.get(i, "rpe"), "rpe"); err != nil {
+		return s, err
+	}
+	return s, nil
+}
@@ -0,0 +1,104 @@
+// Package importers turns the workout history other lifting apps export,
+// and programs kept in spreadsheets, into programs.
+package importers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// The formats Parse reads.
+const (
+	FormatStrong  = "strong"
+	FormatHevy    = "hevy"
+	FormatGeneric = "generic"
+)
+
+// Formats lists every format Parse reads.
+var Formats = []string{FormatStrong, FormatHevy, FormatGeneric}
+
+var defaultNames = map[string]string{
+	FormatStrong:  "Imported from Strong",
+	FormatHevy:    "Imported from Hevy",
+	FormatGeneric: "Imported spreadsheet",
+}
+
+// DefaultWindow is how far back from the most recent workout of a history
+// the current routine reaches when Options.Window is zero.
+const DefaultWindow = 28 * 24 * time.Hour
+
+// Options describe the program an import makes.
+type Options struct {
+	// Name defaults to "Imported from Strong", "Imported from Hevy" or
+	// "Imported spreadsheet".
+	Name     string
+	SharedBy string
+	// Unit is the unit of weights in files that don't name one: Strong
+	// exports, and generic rows without a unit. Kilograms when empty.
+	Unit string
+	// Window picks the workouts that make up the current routine from a
+	// history: those last done within Window of its most recent workout.
+	// DefaultWindow when zero.
+	Window time.Duration
+}
+
+// Result is what an import made of a file.
+type Result struct {
+	Program *db.Program `json:"program"`
+	// Unmapped lists, once each, the exercise names of Program that match
+	// no exercise we know and were kept as written.
+	Unmapped []string `json:"unmapped_exercises"`
+	// Workouts counts the logged sessions read from a history; zero for
+	// the generic layout.
+	Workouts int `json:"workouts"`
+	// Rows counts the data rows read.
+	Rows int `json:"rows"`
+}
+
+// Parse reads a file in the given format. Histories become one day per
+// workout of the current routine, as last performed: working sets, the
+// range of reps done and the top weight of each exercise.
+func Parse(format string, r io.Reader, opts Options) (*Result, error) {
+	switch opts.Unit {
+	case "":
+		opts.Unit = db.UnitKg
+	case db.UnitKg, db.UnitLb:
+	default:
+		return nil, fmt.Errorf("unit %q is not kg or lb", opts.Unit)
+	}
+	if opts.Window == 0 {
+		opts.Window = DefaultWindow
+	}
+
+	var read func(*table, Options) (*Result, error)
+	switch format {
+	case FormatStrong:
+		read = history(strong)
+	case FormatHevy:
+		read = history(hevy)
+	case FormatGeneric:
+		read = generic
+	default:
+		return nil, fmt.Errorf("unknown format %q; want one of %s", format, strings.Join(Formats, ", "))
+	}
+
+	t, err := readTable(r)
+	if err != nil {
+		return nil, err
+	}
+	res, err := read(t, opts)
+	if err != nil {
+		return nil, err
+	}
+	res.Rows = len(t.rows)
+	res.Program.Name = opts.Name
+	if res.Program.Name == "" {
+		res.Program.Name = defaultNames[format]
+	}
+	res.Program.SharedBy = opts.SharedBy
+	return res, nil
+}
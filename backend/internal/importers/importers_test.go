@@ -0,0 +1,141 @@
+package importers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+const strongCSV = `Date;Workout Name;Duration;Exercise Name;Set Order;Weight;Reps;Distance;Seconds;Notes;Workout Notes;RPE
+2023-01-02 18:00:00;Push;1h;Bench Press (Barbell);1;60;8;0;0;;;
+2024-03-04 18:00:00;Push;1h;Bench Press (Barbell);W;40;10;0;0;;;
+2024-03-04 18:00:00;Push;1h;Bench Press (Barbell);1;80;8;0;0;;;8
+2024-03-04 18:00:00;Push;1h;Bench Press (Barbell);2;82,5;6;0;0;;;9
+2024-03-04 18:00:00;Push;1h;Cable Crossover;1;15;12;0;0;;;
+2024-03-04 18:00:00;Push;1h;Treadmill;1;0;0;2;600;;;
+2024-03-06 18:00:00;Pull;1h;Pull Up;1;0;10;0;0;;;
+2024-03-06 18:00:00;Pull;1h;Pull Up;2;0;10;0;0;;;
+2023-06-01 18:00:00;Old Legs;1h;Squat (Barbell);1;100;5;0;0;;;
+`
+
+func TestParseStrong(t *testing.T) {
+	res, err := Parse(FormatStrong, strings.NewReader(strongCSV), Options{SharedBy: "a@example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Imported from Strong", res.Program.Name)
+	assert.Equal(t, 4, res.Workouts)
+	assert.Equal(t, 9, res.Rows)
+	assert.Equal(t, []string{"Cable Crossover"}, res.Unmapped)
+
+	// Old Legs fell out of the routine; Push comes from its latest session
+	require.Len(t, res.Program.Days, 2)
+	push, pull := res.Program.Days[0], res.Program.Days[1]
+	assert.Equal(t, "Push", push.Name)
+	assert.Equal(t, "Pull", pull.Name)
+
+	require.Len(t, push.Exercises, 2, "cardio is dropped")
+	bench := push.Exercises[0]
+	assert.Equal(t, "Bench Press", bench.Name)
+	assert.Equal(t, 2, bench.Sets)
+	assert.Equal(t, "6-8", bench.Reps)
+	require.NotNil(t, bench.Load)
+	assert.Equal(t, 82.5, *bench.Load)
+	assert.Equal(t, db.UnitKg, bench.LoadUnit)
+	require.NotNil(t, bench.RPE)
+	assert.Equal(t, 9.0, *bench.RPE)
+
+	pullUp := pull.Exercises[0]
+	assert.Equal(t, "Pull-Up", pullUp.Name)
+	assert.Equal(t, "10", pullUp.Reps)
+	assert.Nil(t, pullUp.Load, "bodyweight")
+
+	// a longer window brings the old workout back
+	res, err = Parse(FormatStrong, strings.NewReader(strongCSV), Options{Window: 365 * 24 * time.Hour, Unit: db.UnitLb})
+	require.NoError(t, err)
+	require.Len(t, res.Program.Days, 3)
+	assert.Equal(t, "Old Legs", res.Program.Days[0].Name)
+	assert.Equal(t, db.UnitLb, res.Program.Days[0].Exercises[0].LoadUnit)
+}
+
+func TestParseHevy(t *testing.T) {
+	const in = `"title","start_time","end_time","description","exercise_title","superset_id","exercise_notes","set_index","set_type","weight_lbs","reps","distance_km","duration_seconds","rpe"
+"Legs","5 Mar 2024, 07:30","5 Mar 2024, 08:30","","Squat (Barbell)",,"",0,"warmup",135,5,,,
+"Legs","5 Mar 2024, 07:30","5 Mar 2024, 08:30","","Squat (Barbell)",,"",1,"normal",225,5,,,
+"Legs","5 Mar 2024, 07:30","5 Mar 2024, 08:30","","Squat (Barbell)",,"",2,"normal",225,5,,,
+"Legs","5 Mar 2024, 07:30","5 Mar 2024, 08:30","","Squat (Smith Machine)",,"",0,"normal",135,8,,,
+"Legs","5 Mar 2024, 07:30","5 Mar 2024, 08:30","","Leg Extension (Machine)",,"",0,"dropset",90,12,,,
+`
+	res, err := Parse(FormatHevy, strings.NewReader(in), Options{})
+	require.NoError(t, err)
+	require.Len(t, res.Program.Days, 1)
+	ex := res.Program.Days[0].Exercises
+	require.Len(t, ex, 3)
+	assert.Equal(t, "Back Squat", ex[0].Name)
+	assert.Equal(t, 2, ex[0].Sets)
+	assert.Equal(t, 225.0, *ex[0].Load)
+	assert.Equal(t, db.UnitLb, ex[0].LoadUnit, "the column names the unit")
+	assert.Equal(t, "Squat (Smith Machine)", ex[1].Name)
+	assert.Equal(t, "Leg Extension", ex[2].Name)
+	assert.Equal(t, []string{"Squat (Smith Machine)"}, res.Unmapped)
+}
+
+func TestParseGeneric(t *testing.T) {
+	const in = "Day,Exercise,Sets,Reps,Rest,Load,Unit,Percent_TM,RPE,RIR,Tempo\n" +
+		"Upper,Bench Press,4,8-10,90s,,,75%,,,\n" +
+		"Lower,Squat,5,5,3m,100,kg,,8,,\n" +
+		",,,,,,,,,,\n" +
+		"Upper,Face Pulls,3,15,60s,20,lb,,,2,3-1-1-0\n"
+	res, err := Parse(FormatGeneric, strings.NewReader(in), Options{Name: "Mine", SharedBy: "a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "Mine", res.Program.Name)
+	assert.Equal(t, []string{"Face Pulls"}, res.Unmapped)
+
+	require.Len(t, res.Program.Days, 2)
+	upper := res.Program.Days[0]
+	assert.Equal(t, "Upper", upper.Name)
+	require.Len(t, upper.Exercises, 2)
+	assert.Equal(t, 75.0, *upper.Exercises[0].PercentTM)
+	assert.Equal(t, db.UnitLb, upper.Exercises[1].LoadUnit)
+	assert.Equal(t, 2, *upper.Exercises[1].RIR)
+	assert.Equal(t, "Back Squat", res.Program.Days[1].Exercises[0].Name)
+	assert.Equal(t, db.UnitKg, res.Program.Days[1].Exercises[0].LoadUnit)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		format, in, want string
+	}{
+		{FormatGeneric, "day,exercise\nA,B\n", "missing columns: sets, reps"},
+		{FormatGeneric, "day,exercise,sets,reps\nA,B,three,5\n", `line 2: sets "three" is not a positive whole number`},
+		{FormatGeneric, "day,exercise,sets,reps,load,percent_tm\nA,B,3,5,100,80\n", "line 2: load and percent_tm are mutually exclusive"},
+		{FormatHevy, "title,start_time,exercise_title,set_type,reps\n", "weight_kg or weight_lbs"},
+		{FormatStrong, "Date,Workout Name,Exercise Name,Set Order,Weight,Reps\nyesterday,A,B,1,1,1\n", `date "yesterday"`},
+		{FormatStrong, "", "empty"},
+		{"fitbod", "a\n", `unknown format "fitbod"`},
+	} {
+		_, err := Parse(tc.format, strings.NewReader(tc.in), Options{})
+		assert.ErrorContains(t, err, tc.want, tc.in)
+	}
+}
+
+func TestMapExercise(t *testing.T) {
+	for in, want := range map[string]string{
+		"Squat (Barbell)":                         "Back Squat",
+		"Bench Press (Dumbbell)":                  "Dumbbell Bench Press",
+		"Incline Bench Press (Dumbbell)":          "Incline Dumbbell Bench Press",
+		"Triceps Pushdown (Cable - Straight Bar)": "Triceps Pushdown",
+		"Bicep Curl (Dumbbell)":                   "Dumbbell Curl",
+		"Deadlift (Trap bar)":                     "Trap Bar Deadlift",
+		"chin up":                                 "Chin-Up",
+		"Bench Press (Smith Machine)":             "",
+	} {
+		got, ok := MapExercise(in)
+		assert.Equal(t, want, got, in)
+		assert.Equal(t, want != "", ok, in)
+	}
+}
@@ -0,0 +1,125 @@
+package importers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// table is a CSV file whose first row names its columns.
+type table struct {
+	cols  map[string]int
+	rows  [][]string
+	lines []int
+}
+
+// readTable reads a CSV file separated by commas, semicolons or tabs,
+// whichever the header row uses most.
+func readTable(r io.Reader) (*table, error) {
+	br := bufio.NewReader(r)
+	head, err := br.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = strings.TrimPrefix(head, "\ufeff")
+	if strings.TrimSpace(head) == "" {
+		return nil, fmt.Errorf("the file is empty")
+	}
+
+	cr := csv.NewReader(io.MultiReader(strings.NewReader(head), br))
+	cr.Comma = delimiter(head)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	t := &table{cols: map[string]int{}}
+	for i, name := range header {
+		t.cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		t.rows = append(t.rows, row)
+		t.lines = append(t.lines, line)
+	}
+	return t, nil
+}
+
+func delimiter(head string) rune {
+	best, n := ',', strings.Count(head, ",")
+	for _, d := range []rune{';', '\t'} {
+		if c := strings.Count(head, string(d)); c > n {
+			best, n = d, c
+		}
+	}
+	return best
+}
+
+// require fails unless the table has every named column.
+func (t *table) require(cols ...string) error {
+	var missing []string
+	for _, c := range cols {
+		if !t.has(c) {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (t *table) has(col string) bool {
+	_, ok := t.cols[col]
+	return ok
+}
+
+// get returns the trimmed cell of row i in col, or "" when there is none.
+func (t *table) get(i int, col string) string {
+	j, ok := t.cols[col]
+	if !ok || j >= len(t.rows[i]) {
+		return ""
+	}
+	return strings.TrimSpace(t.rows[i][j])
+}
+
+// rowError names the line of row i in err.
+func (t *table) rowError(i int, err error) error {
+	return fmt.Errorf("line %d: %w", t.lines[i], err)
+}
+
+// number parses a cell holding a decimal number, with a point or a comma,
+// as written in locales that use one. Empty cells are zero.
+func number(s, col string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%s %q is not a number", col, s)
+	}
+	return v, nil
+}
+
+// timestamp parses s with the first of layouts that fits.
+func timestamp(s, col string, layouts ...string) (time.Time, error) {
+	for _, l := range layouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s %q is not a date and time", col, s)
+}
@@ -0,0 +1,59 @@
+// Package loads turns the intensity prescriptions stored on a program into
+// concrete loads for a particular lifter.
+package loads
+
+import (
+	"math"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// Resolve fills in Resolved for every percentage prescription in p, on
+// exercises and their individual sets, whose exercise has a training max in
+// maxes. Prescriptions without a matching max are left unresolved.
+func Resolve(p *db.Program, maxes []db.TrainingMax) {
+	byExercise := make(map[string]db.TrainingMax, len(maxes))
+	for _, m := range maxes {
+		byExercise[db.ExerciseKey(m.Exercise)] = m
+	}
+
+	forEachExercise(p, func(ex *db.Exercise) {
+		tm, ok := byExercise[db.ExerciseKey(ex.Name)]
+		if !ok {
+			return
+		}
+		resolve(&ex.Intensity, tm)
+		for si := range ex.SetPrescriptions {
+			resolve(&ex.SetPrescriptions[si].Intensity, tm)
+		}
+	})
+}
+
+func resolve(i *db.Intensity, tm db.TrainingMax) {
+	if i.PercentTM == nil {
+		return
+	}
+	i.Resolved = &db.Resolved{
+		Load:        math.Round(tm.Value**i.PercentTM) / 100,
+		LoadUnit:    tm.Unit,
+		TrainingMax: tm.Value,
+	}
+}
+
+// Convert expresses every absolute and resolved load in p in unit.
+func Convert(p *db.Program, unit string) {
+	forEachExercise(p, func(ex *db.Exercise) {
+		ex.ConvertTo(unit)
+		for si := range ex.SetPrescriptions {
+			ex.SetPrescriptions[si].ConvertTo(unit)
+		}
+	})
+}
+
+func forEachExercise(p *db.Program, fn func(*db.Exercise)) {
+	for di := range p.Days {
+		for ei := range p.Days[di].Exercises {
+			fn(&p.Days[di].Exercises[ei])
+		}
+	}
+}
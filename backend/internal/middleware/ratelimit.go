@@ -0,0 +1,139 @@
+// Package middleware holds the gin middleware wrapped around the API.
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	httpresp "github.com/iraunchy/dyel/backend/internal/http"
+)
+
+// UserHeader identifies the caller for per-user rate limiting. Clients set
+// it themselves, so it is not authenticated: a client can send a new value
+// with every request. The per-IP limit is what bounds such a client.
+const UserHeader = "X-User-ID"
+
+// sweepInterval is how often idle buckets are dropped.
+const sweepInterval = time.Minute
+
+// Limiter is a set of token buckets, one per key, each refilling at Rate
+// tokens per second up to Burst.
+type Limiter struct {
+	Rate  float64
+	Burst int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a Limiter allowing rate requests per second per key,
+// with bursts of up to burst.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		Rate:    rate,
+		Burst:   burst,
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it
+// reports how long until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep forgets buckets that have refilled completely, since a fresh bucket
+// behaves the same.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.Rate >= float64(l.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit rejects requests with 429 and a Retry-After header once the
+// client IP, or the user named in X-User-ID, runs out of tokens. Either
+// limiter may be nil to disable it. The client IP is gin's ClientIP, so the
+// engine's trusted proxies decide whether X-Forwarded-For counts.
+func RateLimit(perIP, perUser *Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if perIP != nil {
+			if ok, wait := perIP.Allow(c.ClientIP()); !ok {
+				tooMany(c, wait)
+				return
+			}
+		}
+		if user := c.GetHeader(UserHeader); perUser != nil && user != "" {
+			if ok, wait := perUser.Allow(user); !ok {
+				tooMany(c, wait)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+func tooMany(c *gin.Context, wait time.Duration) {
+	secs := int(math.Ceil(wait.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+	httpresp.Error(c, http.StatusTooManyRequests, fmt.Errorf("rate limit exceeded; retry in %ds", secs))
+	c.Abort()
+}
+
+// MaxBodySize caps request bodies at n bytes. Reading past the cap fails
+// with *http.MaxBytesError, which handlers answer with 413.
+func MaxBodySize(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > n {
+			httpresp.Error(c, http.StatusRequestEntityTooLarge,
+				fmt.Errorf("request body is %d bytes; the limit is %d", c.Request.ContentLength, n))
+			c.Abort()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		c.Next()
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterRefills(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("a")
+		assert.True(t, ok, "burst %d", i)
+	}
+	ok, wait := l.Allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	ok, _ = l.Allow("b")
+	assert.True(t, ok, "buckets are per key")
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Hour)
+	l.Allow("c")
+	assert.Len(t, l.buckets, 1, "idle buckets are swept")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	r := gin.New()
+	r.Use(RateLimit(NewLimiter(1, 2), NewLimiter(1, 1)))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(user string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/", nil)
+		if user != "" {
+			req.Header.Set(UserHeader, user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, get("alice").Code)
+	w := get("alice")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "user bucket is empty")
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	w = get("")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "IP bucket is empty")
+}
+
+func TestRateLimitIgnoresForwardedForFromUntrustedPeers(t *testing.T) {
+	r := gin.New()
+	assert.NoError(t, r.SetTrustedProxies(nil))
+	r.Use(RateLimit(NewLimiter(1, 1), nil))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(forwardedFor string) int {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "203.0.113.7:41000"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, get("198.51.100.2"), "a new X-Forwarded-For is not a new bucket")
+}
+
+func TestMaxBodySize(t *testing.T) {
+	r := gin.New()
+	r.Use(MaxBodySize(8))
+	r.POST("/", func(c *gin.Context) {
+		var v any
+		if err := c.ShouldBindJSON(&v); err != nil {
+			c.Status(http.StatusTeapot)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("POST", "/", strings.NewReader(`{"a":1}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("POST", "/", strings.NewReader(`{"a":"long"}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
@@ -0,0 +1,309 @@
+// Package openapi generates an OpenAPI 3.1 document from the routes
+// registered on a gin engine and the Go types bound by their handlers.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Document is the root OpenAPI object.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// PathItem holds the operations for one path, keyed by lower-case method.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Operation documents one route. URI, Query, Header and Body are zero values
+// of the structs the handler binds with `uri`, `form`, `header` and `json`
+// tags; Response is a zero value of what it writes on success. Nil fields are
+// omitted.
+type Operation struct {
+	ID       string
+	Summary  string
+	Tag      string
+	URI      any
+	Query    any
+	Header   any
+	Body     any
+	Response any
+	// BodyType overrides application/json for the request body.
+	BodyType string
+	// ContentType overrides application/json for the success response.
+	ContentType string
+	Status      int
+	// Extra lists further response codes. 3xx carry no body; 4xx and 5xx
+	// carry the Error schema.
+	Extra []int
+}
+
+// Key returns the lookup key used for a route: "METHOD /gin/:path".
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+type generator struct {
+	components map[string]*Schema
+	// owners maps each component name to the struct it was registered for.
+	owners map[string]reflect.Type
+	// shared are the structs found in both request and response bodies.
+	// Their request side is a separate <Name>Input component.
+	shared map[reflect.Type]bool
+	// response is set while walking a response body, where every field
+	// not tagged omitempty is always present.
+	response bool
+}
+
+// Build produces the document for routes, looking each one up in ops. It
+// fails when a route has no Operation, so a new route cannot ship without
+// being documented.
+func Build(info Info, routes gin.RoutesInfo, ops map[string]Operation) (*Document, error) {
+	g := &generator{components: map[string]*Schema{}, owners: map[string]reflect.Type{}, shared: sharedStructs(routes, ops)}
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+	}
+
+	var problems []string
+	for _, r := range routes {
+		key := Key(r.Method, r.Path)
+		op, ok := ops[key]
+		if !ok {
+			problems = append(problems, "undocumented route "+key)
+			continue
+		}
+
+		path := openAPIPath(r.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(r.Method)] = g.operation(op)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("openapi: %s", strings.Join(problems, "; "))
+	}
+
+	g.components["Error"] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"error": {Type: "string"}},
+		Required:   []string{"error"},
+	}
+	doc.Components.Schemas = g.components
+	return doc, nil
+}
+
+// Unrouted returns the keys in ops that match none of routes, sorted.
+func Unrouted(routes gin.RoutesInfo, ops map[string]Operation) []string {
+	seen := map[string]bool{}
+	for _, r := range routes {
+		seen[Key(r.Method, r.Path)] = true
+	}
+	var out []string
+	for key := range ops {
+		if !seen[key] {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (g *generator) operation(op Operation) *OperationObject {
+	out := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Responses:   map[string]*Response{},
+	}
+	if op.Tag != "" {
+		out.Tags = []string{op.Tag}
+	}
+
+	if op.URI != nil {
+		out.Parameters = append(out.Parameters, g.parameters(op.URI, "uri", "path")...)
+	}
+	if op.Query != nil {
+		out.Parameters = append(out.Parameters, g.parameters(op.Query, "form", "query")...)
+	}
+	if op.Header != nil {
+		out.Parameters = append(out.Parameters, g.parameters(op.Header, "header", "header")...)
+	}
+	if op.Body != nil {
+		bt := op.BodyType
+		if bt == "" {
+			bt = "application/json"
+		}
+		out.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				bt: {Schema: g.schemaFor(reflect.TypeOf(op.Body))},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := &Response{Description: http.StatusText(status)}
+	if op.Response != nil && status != http.StatusNoContent {
+		ct := op.ContentType
+		if ct == "" {
+			ct = "application/json"
+		}
+		g.response = true
+		resp.Content = map[string]MediaType{ct: {Schema: g.schemaFor(reflect.TypeOf(op.Response))}}
+		g.response = false
+	}
+	out.Responses[strconv.Itoa(status)] = resp
+
+	errBody := map[string]MediaType{
+		"application/json": {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+	}
+	if op.URI != nil || op.Query != nil || op.Body != nil {
+		out.Responses["400"] = &Response{Description: "Bad Request", Content: errBody}
+	}
+	out.Responses["500"] = &Response{Description: "Internal Server Error", Content: errBody}
+	for _, code := range op.Extra {
+		r := &Response{Description: http.StatusText(code)}
+		if code >= 400 {
+			r.Content = errBody
+		}
+		out.Responses[strconv.Itoa(code)] = r
+	}
+	return out
+}
+
+// sharedStructs returns the structs that the routed operations use in both
+// a request body and a response.
+func sharedStructs(routes gin.RoutesInfo, ops map[string]Operation) map[reflect.Type]bool {
+	in, out := map[reflect.Type]bool{}, map[reflect.Type]bool{}
+	for _, r := range routes {
+		op, ok := ops[Key(r.Method, r.Path)]
+		if !ok {
+			continue
+		}
+		if op.Body != nil {
+			collectStructs(reflect.TypeOf(op.Body), in)
+		}
+		if op.Response != nil && op.Status != http.StatusNoContent {
+			collectStructs(reflect.TypeOf(op.Response), out)
+		}
+	}
+	shared := map[reflect.Type]bool{}
+	for t := range in {
+		if out[t] {
+			shared[t] = true
+		}
+	}
+	return shared
+}
+
+// collectStructs adds t and every struct reachable through its fields to
+// seen.
+func collectStructs(t reflect.Type, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	if _, ok := knownTypes[t]; ok {
+		return
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() && f.Tag.Get("json") != "-" {
+			collectStructs(f.Type, seen)
+		}
+	}
+}
+
+// parameters turns the tagged fields of v into path or query parameters.
+func (g *generator) parameters(v any, tag, in string) []Parameter {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		schema := g.schemaFor(f.Type)
+		required := applyBinding(&schema, f.Tag.Get("binding"))
+		params = append(params, Parameter{
+			Name:     name,
+			In:       in,
+			Required: required || in == "path",
+			Schema:   schema,
+		})
+	}
+	return params
+}
+
+// openAPIPath rewrites gin's :param and *param segments as {param}.
+func openAPIPath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segs[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
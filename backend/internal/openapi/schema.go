@@ -0,0 +1,284 @@
+package openapi
+
+import (
+	"database/sql"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema is the subset of JSON Schema (2020-12, as used by OpenAPI 3.1) that
+// the generator emits.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+}
+
+// knownTypes are structs that marshal to something other than their fields.
+var knownTypes = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(time.Time{}): func() *Schema {
+		return &Schema{Type: "string", Format: "date-time"}
+	},
+	reflect.TypeOf(sql.NullTime{}): func() *Schema {
+		return &Schema{Type: []string{"string", "null"}, Format: "date-time"}
+	},
+	reflect.TypeOf(gorm.DeletedAt{}): func() *Schema {
+		return &Schema{Type: []string{"string", "null"}, Format: "date-time"}
+	},
+}
+
+// schemaFor returns the schema for t, registering named structs as
+// components and referencing them by $ref. A shared struct met outside a
+// response is registered with an Input suffix, and a struct whose name is
+// already taken by another package's is prefixed with its package name.
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if known, ok := knownTypes[t]; ok {
+		return known()
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := t.Name()
+		if owner, ok := g.owners[name]; ok && owner != t {
+			pkg := path.Base(t.PkgPath())
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		g.owners[name] = t
+		if !g.response && g.shared[t] {
+			name += "Input"
+		}
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = nil // placeholder breaks recursion
+			g.components[name] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case t.Kind() == reflect.Struct:
+		return g.structSchema(t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	default:
+		// interface{} and friends: any JSON value
+		return &Schema{}
+	}
+}
+
+// structSchema builds an object schema from exported fields, honouring json
+// tags, embedded structs and gin binding rules. In a response, fields not
+// tagged omitempty are required, and those Go marshals as null when unset
+// are nullable.
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := g.schemaFor(f.Type)
+		binding := f.Tag.Get("binding")
+		required := applyBinding(&prop, binding)
+		if g.response && !omitted(opts) {
+			required = true
+			// an optional enum comes back empty when it was left out
+			if prop.Type == "string" && len(prop.Enum) > 0 && strings.HasPrefix(binding, "omitempty") {
+				prop.Enum = append(prop.Enum, "")
+			}
+			switch f.Type.Kind() {
+			case reflect.Pointer, reflect.Slice, reflect.Map:
+				prop = orNull(prop)
+			}
+		}
+		if required {
+			s.Required = appendUnique(s.Required, name)
+		}
+		// an outer field shadows an embedded one with the same name
+		s.Properties[name] = prop
+	}
+}
+
+// applyBinding translates gin/validator rules onto the schema and reports
+// whether the field is required. Rules after "dive" apply to slice items.
+func applyBinding(s **Schema, tag string) (required bool) {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	target := *s
+	for _, rule := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(rule, "=")
+		if key == "dive" {
+			if target.Items == nil {
+				return required
+			}
+			// copy so the shared $ref schema is not mutated
+			items := *target.Items
+			target.Items = &items
+			target = target.Items
+			continue
+		}
+		if target.Ref != "" {
+			continue
+		}
+		switch key {
+		case "required":
+			if target == *s {
+				required = true
+			}
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "url", "uri":
+			target.Format = "uri"
+		case "email":
+			target.Format = "email"
+		case "oneof":
+			for _, v := range strings.Fields(val) {
+				target.Enum = append(target.Enum, enumValue(target, v))
+			}
+		case "min", "gte":
+			setBound(target, val, true)
+		case "max", "lte":
+			setBound(target, val, false)
+		}
+	}
+	return required
+}
+
+func enumValue(s *Schema, v string) any {
+	if s.Type == "integer" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+func setBound(s *Schema, val string, lower bool) {
+	n, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "string":
+		i := int(n)
+		if lower {
+			s.MinLength = &i
+		} else {
+			s.MaxLength = &i
+		}
+	case "array":
+		i := int(n)
+		if lower {
+			s.MinItems = &i
+		} else {
+			s.MaxItems = &i
+		}
+	default:
+		if lower {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+// omitted reports whether json tag options leave a field out when empty.
+func omitted(opts string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == "omitempty" || o == "omitzero" {
+			return true
+		}
+	}
+	return false
+}
+
+// orNull widens s to also allow null.
+func orNull(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+	switch t := s.Type.(type) {
+	case string:
+		s.Type = []string{t, "null"}
+	case []string:
+		if !slices.Contains(t, "null") {
+			s.Type = append(t, "null")
+		}
+	}
+	if len(s.Enum) > 0 {
+		s.Enum = append(s.Enum, nil)
+	}
+	return s
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
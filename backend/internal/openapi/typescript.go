@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TypeScript writes the document's component schemas as TypeScript
+// interfaces, so the frontend imports the API's types instead of copying
+// them. Properties are optional unless the schema requires them.
+func TypeScript(doc *Document, header string) []byte {
+	var b bytes.Buffer
+	b.WriteString(header)
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := doc.Components.Schemas[name]
+		if s == nil || s.Type != "object" || s.AdditionalProperties != nil {
+			fmt.Fprintf(&b, "\nexport type %s = %s\n", name, tsType(s, ""))
+			continue
+		}
+		fmt.Fprintf(&b, "\nexport interface %s %s\n", name, tsObject(s, ""))
+	}
+	return b.Bytes()
+}
+
+func tsObject(s *Schema, indent string) string {
+	if len(s.Properties) == 0 {
+		return "{}"
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range names {
+		opt := "?"
+		for _, r := range s.Required {
+			if r == name {
+				opt = ""
+			}
+		}
+		fmt.Fprintf(&b, "%s  %s%s: %s\n", indent, name, opt, tsType(s.Properties[name], indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func tsType(s *Schema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref != "" {
+		return s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	}
+	if len(s.AnyOf) > 0 {
+		parts := make([]string, len(s.AnyOf))
+		for i, sub := range s.AnyOf {
+			parts[i] = tsType(sub, indent)
+		}
+		return strings.Join(parts, " | ")
+	}
+	if len(s.Enum) > 0 {
+		vals := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			lit, _ := json.Marshal(v)
+			vals[i] = string(lit)
+		}
+		return strings.Join(vals, " | ")
+	}
+
+	var types []string
+	switch t := s.Type.(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	case []any:
+		for _, v := range t {
+			types = append(types, fmt.Sprint(v))
+		}
+	}
+	if len(types) == 0 {
+		return "unknown"
+	}
+
+	out := make([]string, len(types))
+	for i, t := range types {
+		switch t {
+		case "integer", "number":
+			out[i] = "number"
+		case "array":
+			item := tsType(s.Items, indent)
+			if strings.Contains(item, " ") {
+				item = "(" + item + ")"
+			}
+			out[i] = item + "[]"
+		case "object":
+			if s.AdditionalProperties != nil {
+				out[i] = "Record<string, " + tsType(s.AdditionalProperties, indent) + ">"
+			} else {
+				out[i] = tsObject(s, indent)
+			}
+		default: // string, boolean, null
+			out[i] = t
+		}
+	}
+	return strings.Join(out, " | ")
+}
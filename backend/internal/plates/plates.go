@@ -0,0 +1,174 @@
+// Package plates works out how to load a barbell from the plates at hand.
+package plates
+
+import (
+	"math"
+	"sort"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// Defaults are stocked gym inventories for each unit.
+var Defaults = map[string]db.PlateInventory{
+	db.UnitKg: {
+		Name: "Standard kg", Unit: db.UnitKg, Bar: 20,
+		Plates: []db.Plate{
+			{Weight: 25, Count: 8}, {Weight: 20, Count: 2}, {Weight: 15, Count: 2}, {Weight: 10, Count: 2},
+			{Weight: 5, Count: 2}, {Weight: 2.5, Count: 2}, {Weight: 1.25, Count: 2},
+		},
+	},
+	db.UnitLb: {
+		Name: "Standard lb", Unit: db.UnitLb, Bar: 45,
+		Plates: []db.Plate{
+			{Weight: 45, Count: 8}, {Weight: 35, Count: 2}, {Weight: 25, Count: 2},
+			{Weight: 10, Count: 2}, {Weight: 5, Count: 2}, {Weight: 2.5, Count: 2},
+		},
+	},
+}
+
+// scale turns weights into whole hundredths so sums are exact.
+const scale = 100
+
+// MaxTarget is the heaviest load Calculate works towards, the most
+// POST /plates/calculate accepts. Heavier targets get the nearest load up
+// to MaxTarget, so stored loads can't make it slow.
+const MaxTarget = 1000
+
+// Breakdown is how to load the bar for a target weight. PerSide lists the
+// plates for one side, heaviest first. When Exact is false, Achieved is the
+// nearest load the inventory can make.
+type Breakdown struct {
+	Target   float64    `json:"target"`
+	Achieved float64    `json:"achieved"`
+	Exact    bool       `json:"exact"`
+	Unit     string     `json:"unit"`
+	Bar      float64    `json:"bar"`
+	PerSide  []db.Plate `json:"per_side"`
+}
+
+// Calculate finds the load nearest to target, in inv.Unit, that the bar and
+// plates in inv can make, using as few and as heavy plates as possible.
+// Ties between a lighter and a heavier load go to the lighter one.
+func Calculate(target float64, inv db.PlateInventory) Breakdown {
+	b := Breakdown{Target: target, Unit: inv.Unit, Bar: inv.Bar, PerSide: []db.Plate{}}
+
+	want := toUnits((target - inv.Bar) / 2)
+	side := min(want, toUnits((MaxTarget-inv.Bar)/2))
+
+	// One denomination per plate weight, heaviest first, with the number of
+	// pairs of it on hand. No sum beyond twice the per-side target can be
+	// nearer than an empty bar, which bounds both the pairs worth considering
+	// and the tables below; MaxTarget bounds the per-side target.
+	var denoms []denomination
+	plates := append([]db.Plate(nil), inv.Plates...)
+	sort.Slice(plates, func(i, j int) bool { return plates[i].Weight > plates[j].Weight })
+	total, full := 0, 0
+	for _, p := range plates {
+		w := toUnits(p.Weight)
+		if w <= 0 || side <= 0 {
+			continue
+		}
+		full += p.Count / 2 * w
+		n := min(p.Count/2, 2*side/w, math.MaxUint8)
+		if n > 0 {
+			denoms = append(denoms, denomination{w, n})
+			total += n * w
+		}
+	}
+
+	total = min(total, 2*side)
+	if side <= 0 || len(denoms) == 0 {
+		b.Achieved = inv.Bar
+		b.Exact = toUnits(target) == toUnits(inv.Bar)
+		return b
+	}
+
+	counts := map[int]int{}
+	best := full
+	if full <= side {
+		// Every plate on is as near as the inventory gets.
+		for _, d := range denoms {
+			counts[d.weight] += d.pairs
+		}
+	} else {
+		best = nearest(denoms, total, side, counts)
+	}
+	for _, p := range plates {
+		w := toUnits(p.Weight)
+		if n := counts[w]; n > 0 {
+			b.PerSide = append(b.PerSide, db.Plate{Weight: p.Weight, Count: n})
+			delete(counts, w)
+		}
+	}
+
+	b.Achieved = inv.Bar + 2*fromUnits(best)
+	b.Exact = best == want
+	return b
+}
+
+// denomination is one plate weight, in units, with the number of pairs of
+// it worth considering.
+type denomination struct{ weight, pairs int }
+
+// nearest finds the per-side sum of denoms, up to total, nearest to side
+// and fills counts with the pairs of each weight it takes.
+func nearest(denoms []denomination, total, side int, counts map[int]int) int {
+	// fewest[w] is the fewest pairs summing to w per side, with heft[w]
+	// (the sum of their squared weights) breaking ties towards bigger
+	// plates. take[i][w] is how many pairs of denomination i that best sum
+	// uses, so memory grows with the number of plate weights rather than
+	// the number of plates: at most 30 × 100,001 bytes, as side is at most
+	// half of MaxTarget.
+	const unreachable = math.MaxInt
+	fewest := make([]int, total+1)
+	heft := make([]int, total+1)
+	for w := range fewest {
+		fewest[w] = unreachable
+	}
+	fewest[0] = 0
+	take := make([][]uint8, len(denoms))
+	for i, d := range denoms {
+		take[i] = make([]uint8, total+1)
+		// Going down, every w-k*d.weight still holds the best sum without
+		// this denomination.
+		for w := total; w >= d.weight; w-- {
+			for k := 1; k <= d.pairs && k*d.weight <= w; k++ {
+				prev := fewest[w-k*d.weight]
+				if prev == unreachable {
+					continue
+				}
+				n, h := prev+k, heft[w-k*d.weight]+k*d.weight*d.weight
+				if n < fewest[w] || n == fewest[w] && h > heft[w] {
+					fewest[w], heft[w] = n, h
+					take[i][w] = uint8(k)
+				}
+			}
+		}
+	}
+
+	best := -1
+	for w := range fewest {
+		if fewest[w] == unreachable {
+			continue
+		}
+		if best < 0 || abs(w-side) < abs(best-side) {
+			best = w
+		}
+	}
+
+	for i, w := len(denoms)-1, best; i >= 0 && w > 0; i-- {
+		k := int(take[i][w])
+		counts[denoms[i].weight] += k
+		w -= k * denoms[i].weight
+	}
+	return best
+}
+
+func toUnits(v float64) int   { return int(math.Round(v * scale)) }
+func fromUnits(u int) float64 { return float64(u) / scale }
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
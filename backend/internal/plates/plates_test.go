@@ -0,0 +1,81 @@
+package plates
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+func TestCalculateExact(t *testing.T) {
+	b := Calculate(142.5, Defaults[db.UnitKg])
+	assert.True(t, b.Exact)
+	assert.Equal(t, 142.5, b.Achieved)
+	assert.Equal(t, []db.Plate{{Weight: 25, Count: 2}, {Weight: 10, Count: 1}, {Weight: 1.25, Count: 1}}, b.PerSide)
+}
+
+func TestCalculateNearest(t *testing.T) {
+	inv := db.PlateInventory{Unit: db.UnitKg, Bar: 20, Plates: []db.Plate{{Weight: 20, Count: 2}, {Weight: 10, Count: 2}}}
+
+	b := Calculate(67, inv)
+	assert.False(t, b.Exact)
+	assert.Equal(t, 60.0, b.Achieved)
+	assert.Equal(t, []db.Plate{{Weight: 20, Count: 1}}, b.PerSide)
+
+	b = Calculate(500, inv)
+	assert.Equal(t, 80.0, b.Achieved, "everything on the bar")
+
+	b = Calculate(15, inv)
+	assert.Equal(t, 20.0, b.Achieved)
+	assert.Empty(t, b.PerSide)
+}
+
+func TestCalculateBeyondMaxTarget(t *testing.T) {
+	inv := db.PlateInventory{Unit: db.UnitKg, Bar: 20}
+	for i := range 30 {
+		inv.Plates = append(inv.Plates, db.Plate{Weight: 99.5 - float64(i)/2, Count: 100})
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b := Calculate(1e6, inv)
+	runtime.ReadMemStats(&after)
+
+	assert.False(t, b.Exact)
+	assert.LessOrEqual(t, b.Achieved, float64(MaxTarget))
+	assert.Greater(t, b.Achieved, float64(MaxTarget)-100)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(8<<20), "allocated bytes")
+
+	// without enough plates to get near, everything goes on without a search
+	few := db.PlateInventory{Unit: db.UnitKg, Bar: 20, Plates: []db.Plate{{Weight: 25, Count: 4}}}
+	b = Calculate(1e6, few)
+	assert.Equal(t, 120.0, b.Achieved)
+	assert.Equal(t, []db.Plate{{Weight: 25, Count: 2}}, b.PerSide)
+}
+
+func TestCalculatePrefersFewerPlates(t *testing.T) {
+	inv := db.PlateInventory{Unit: db.UnitLb, Bar: 45, Plates: []db.Plate{{Weight: 25, Count: 4}, {Weight: 45, Count: 2}, {Weight: 10, Count: 4}, {Weight: 5, Count: 2}}}
+	b := Calculate(135, inv)
+	assert.Equal(t, []db.Plate{{Weight: 45, Count: 1}}, b.PerSide)
+}
+
+func TestCalculateLargestInput(t *testing.T) {
+	// the most the API accepts: 30 plate weights, 100 of each, and a target
+	// of 1000 on an empty bar
+	inv := db.PlateInventory{Unit: db.UnitKg}
+	for i := 1; i <= 30; i++ {
+		inv.Plates = append(inv.Plates, db.Plate{Weight: float64(i) / 2, Count: 100})
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b := Calculate(1000, inv)
+	runtime.ReadMemStats(&after)
+
+	assert.True(t, b.Exact)
+	assert.Equal(t, 1000.0, b.Achieved)
+	assert.Equal(t, []db.Plate{{Weight: 15, Count: 33}, {Weight: 5, Count: 1}}, b.PerSide)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(8<<20), "allocated bytes")
+}
@@ -0,0 +1,35 @@
+package printsheet
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+//go:embed sheet.html
+var sheetHTML string
+
+var htmlSheet = template.Must(template.New("sheet").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(sheetHTML))
+
+// HTML writes s as a standalone page whose days print one to a sheet.
+func (s *Sheet) HTML(w io.Writer) error {
+	return htmlSheet.Execute(w, s)
+}
+
+// QRPath draws the dark modules of the QR code as an SVG path, one unit
+// per module.
+func (s *Sheet) QRPath() string {
+	var b strings.Builder
+	for y, line := range s.QR {
+		for x, dark := range line {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package printsheet
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Page geometry in millimetres, on A4.
+const (
+	margin    = 12.0
+	qrSize    = 26.0
+	headRow   = 7.0
+	plainRow  = 9.0
+	targetRow = 12.0
+	notesBox  = 30.0
+	minName   = 60.0
+	maxBox    = 14.0
+)
+
+// Fixed column widths: sets, reps and rest.
+var fixedCols = [3]float64{12, 24, 14}
+
+// PDF writes s as an A4 document with a page per day. A day too long for
+// one page continues on the next.
+func (s *Sheet) PDF(w io.Writer) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(false, margin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(tr(s.Program), false)
+	pdf.SetCreator("dyel", false)
+
+	r := &pdfRenderer{pdf: pdf, tr: tr, sheet: s}
+	if len(s.Pages) == 0 {
+		r.header(Page{Day: "This program has no days yet."})
+	}
+	for i, p := range s.Pages {
+		r.page(i, p)
+	}
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+type pdfRenderer struct {
+	pdf   *gofpdf.Fpdf
+	tr    func(string) string
+	sheet *Sheet
+}
+
+func (r *pdfRenderer) page(i int, p Page) {
+	pdf := r.pdf
+	pageW, pageH := pdf.GetPageSize()
+	width := pageW - 2*margin
+	box := min(maxBox, (width-minName-fixedCols[0]-fixedCols[1]-fixedCols[2])/float64(max(p.Columns, 1)))
+	name := width - fixedCols[0] - fixedCols[1] - fixedCols[2] - box*float64(p.Columns)
+	bottom := pageH - margin - 6 // leave room for the footer
+
+	r.header(p)
+	r.tableHead(p, name, box)
+	for _, row := range p.Rows {
+		h := plainRow
+		if row.Target != "" {
+			h = targetRow
+		}
+		if pdf.GetY()+h > bottom {
+			r.footer(i)
+			r.header(Page{Day: p.Day + " (continued)"})
+			r.tableHead(p, name, box)
+		}
+		r.tableRow(p, row, name, box, h)
+	}
+	if y := pdf.GetY() + 6; y+notesBox <= bottom {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.Rect(margin, y, width, notesBox, "D")
+		pdf.Text(margin+1.5, y+4, "Notes")
+	}
+	r.footer(i)
+}
+
+// header starts a page with the program and day names and the QR code.
+func (r *pdfRenderer) header(p Page) {
+	pdf := r.pdf
+	pdf.AddPage()
+	pageW, _ := pdf.GetPageSize()
+	textW := pageW - 2*margin - qrSize - 4
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(textW, 9, r.fit(r.sheet.Program, textW), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 13)
+	pdf.CellFormat(textW, 7, r.fit(p.Day, textW), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	meta := "Date: ______________   Bodyweight: ________"
+	if r.sheet.Author != "" {
+		meta = "By " + r.sheet.Author + "   " + meta
+	}
+	pdf.CellFormat(textW, 6, r.fit(meta, textW), "", 2, "L", false, 0, "")
+
+	r.qr(pageW-margin-qrSize, margin, qrSize)
+	pdf.SetY(max(pdf.GetY(), margin+qrSize) + 4)
+}
+
+func (r *pdfRenderer) qr(x, y, size float64) {
+	n := len(r.sheet.QR)
+	if n == 0 {
+		return
+	}
+	m := size / float64(n)
+	r.pdf.SetFillColor(0, 0, 0)
+	for row, line := range r.sheet.QR {
+		for col, dark := range line {
+			if dark {
+				r.pdf.Rect(x+float64(col)*m, y+float64(row)*m, m, m, "F")
+			}
+		}
+	}
+}
+
+func (r *pdfRenderer) tableHead(p Page, name, box float64) {
+	pdf := r.pdf
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(238, 238, 238)
+	pdf.SetX(margin)
+	for k, label := range []string{"Exercise", "Sets", "Reps", "Rest"} {
+		w := name
+		if k > 0 {
+			w = fixedCols[k-1]
+		}
+		pdf.CellFormat(w, headRow, label, "1", 0, "L", true, 0, "")
+	}
+	for k := range p.Columns {
+		pdf.CellFormat(box, headRow, strconv.Itoa(k+1), "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(headRow)
+}
+
+func (r *pdfRenderer) tableRow(p Page, row Row, name, box, h float64) {
+	pdf := r.pdf
+	x, y := margin, pdf.GetY()
+
+	pdf.Rect(x, y, name, h, "D")
+	label := row.Exercise
+	if row.Group != "" {
+		label = row.Group + "  " + label
+	}
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.Text(x+1.5, y+5.5, r.fit(label, name-3))
+	if row.Target != "" {
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(51, 51, 51)
+		pdf.Text(x+1.5, y+9.5, r.fit(row.Target, name-3))
+		pdf.SetTextColor(0, 0, 0)
+	}
+	x += name
+
+	pdf.SetFont("Helvetica", "", 10)
+	for k, text := range []string{row.Sets, row.Reps, row.Rest} {
+		w := fixedCols[k]
+		pdf.Rect(x, y, w, h, "D")
+		pdf.Text(x+1.5, y+5.5, r.fit(text, w-3))
+		x += w
+	}
+
+	pdf.SetFillColor(221, 221, 221)
+	for k := range p.Columns {
+		style := "D"
+		if k >= row.Boxes {
+			style = "FD"
+		}
+		pdf.Rect(x, y, box, h, style)
+		x += box
+	}
+	pdf.SetXY(margin, y+h)
+}
+
+func (r *pdfRenderer) footer(i int) {
+	pdf := r.pdf
+	pageW, pageH := pdf.GetPageSize()
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(51, 51, 51)
+	y := pageH - margin
+	page := fmt.Sprintf("Day %d of %d", i+1, len(r.sheet.Pages))
+	pdf.Text(margin, y, r.fit(r.sheet.Link, pageW-2*margin-pdf.GetStringWidth(page)-4))
+	pdf.Text(pageW-margin-pdf.GetStringWidth(page), y, page)
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// fit translates s for the core fonts and shortens it to fit in w at the
+// current font size.
+func (r *pdfRenderer) fit(s string, w float64) string {
+	s = r.tr(s)
+	if r.pdf.GetStringWidth(s) <= w {
+		return s
+	}
+	ellipsis := r.tr("…")
+	for len(s) > 0 && r.pdf.GetStringWidth(s+ellipsis) > w {
+		s = s[:len(s)-1]
+	}
+	return s + ellipsis
+}
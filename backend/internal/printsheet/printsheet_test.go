@@ -0,0 +1,95 @@
+package printsheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+func program() *db.Program {
+	load, pct, rpe := 100.0, 75.0, 8.0
+	return &db.Program{
+		Name:     "Upper/Lower",
+		SharedBy: "jane@example.com",
+		Days: []db.Day{
+			{Name: "Mon – Upper", Exercises: []db.Exercise{
+				{Name: "Bench Press", Sets: 4, Reps: "8-10", Rest: "90s", Intensity: db.Intensity{Load: &load, LoadUnit: "kg"}},
+				{Name: "Chin-Up", Sets: 3, Reps: "AMRAP", Rest: "2m", Group: "A1"},
+			}},
+			{Name: "Thu – Lower", Exercises: []db.Exercise{
+				{Name: "Back Squat", Rest: "3m", SetPrescriptions: []db.SetPrescription{
+					{Type: db.SetWarmup, Reps: "5"},
+					{Type: db.SetWorking, Reps: "5", Intensity: db.Intensity{PercentTM: &pct}},
+					{Type: db.SetAMRAP, Reps: "5", Intensity: db.Intensity{PercentTM: &pct}},
+				}},
+				{Name: "Romanian Deadlift", Sets: 12, Reps: "8", Intensity: db.Intensity{RPE: &rpe}},
+			}},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(program(), "https://dyel.example.com/programs/1")
+	require.NoError(t, err)
+	require.Len(t, s.Pages, 2)
+	assert.NotEmpty(t, s.QR)
+	assert.Len(t, s.QR[0], len(s.QR), "square")
+
+	upper := s.Pages[0]
+	assert.Equal(t, 4, upper.Columns)
+	assert.Equal(t, Row{Exercise: "Bench Press", Sets: "4", Reps: "8-10", Rest: "90s", Target: "100 kg", Boxes: 4}, upper.Rows[0])
+
+	lower := s.Pages[1]
+	assert.Equal(t, MaxColumns, lower.Columns)
+	assert.Equal(t, "W5 / 5 / 5+", lower.Rows[0].Reps)
+	assert.Equal(t, "– / 75% TM / 75% TM", lower.Rows[0].Target)
+	assert.Equal(t, "12", lower.Rows[1].Sets)
+	assert.Equal(t, MaxColumns, lower.Rows[1].Boxes)
+	assert.Equal(t, "RPE 8", lower.Rows[1].Target)
+}
+
+func TestHTML(t *testing.T) {
+	s, err := New(program(), "https://dyel.example.com/programs/1")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, s.HTML(&out))
+	html := out.String()
+	assert.Equal(t, 2, strings.Count(html, "<section>"))
+	assert.Contains(t, html, "Mon – Upper")
+	assert.Contains(t, html, `<span class="group">A1</span>Chin-Up`)
+	assert.Contains(t, html, "Day 2 of 2")
+	assert.Contains(t, html, `<path d="M`)
+	assert.Equal(t, 4+3+3+MaxColumns, strings.Count(html, `class="box"`))
+
+	s, err = New(program(), "")
+	require.NoError(t, err)
+	assert.Empty(t, s.QR)
+	out.Reset()
+	require.NoError(t, s.HTML(&out))
+	assert.NotContains(t, out.String(), "<svg")
+}
+
+func TestPDF(t *testing.T) {
+	p := program()
+	// enough rows to run a day over two pages
+	for i := range 30 {
+		p.Days[0].Exercises = append(p.Days[0].Exercises, db.Exercise{Name: "Curl " + strings.Repeat("I", i), Sets: 3, Reps: "12"})
+	}
+	s, err := New(p, "https://dyel.example.com/programs/1")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, s.PDF(&out))
+	assert.True(t, bytes.HasPrefix(out.Bytes(), []byte("%PDF-")))
+	assert.Equal(t, 3, bytes.Count(out.Bytes(), []byte("/Type /Page\n")))
+
+	out.Reset()
+	s, err = New(&db.Program{Name: "Empty"}, "")
+	require.NoError(t, err)
+	require.NoError(t, s.PDF(&out))
+	assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte("/Type /Page\n")))
+}
@@ -0,0 +1,110 @@
+// Package printsheet lays programs out as sheets to print and train from:
+// a page per day with a table of exercises, blank boxes to write in the
+// weights used, and a QR code back to the program.
+package printsheet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/render"
+)
+
+// MaxColumns caps the weight boxes per row; longer exercises share the
+// last box.
+const MaxColumns = 8
+
+// Sheet is a program laid out for printing.
+type Sheet struct {
+	Program string
+	Author  string
+	// Link is the address the QR code leads to.
+	Link string
+	// QR holds the QR code's modules, quiet zone included, row by row;
+	// true is dark. It is empty when there is no Link.
+	QR    [][]bool
+	Pages []Page
+}
+
+// Page is one day.
+type Page struct {
+	Day string
+	// Columns is how many weight boxes the widest row has.
+	Columns int
+	Rows    []Row
+}
+
+// Row is one exercise. Boxes is its number of sets, up to MaxColumns.
+type Row struct {
+	Group    string
+	Exercise string
+	Sets     string
+	Reps     string
+	Rest     string
+	Target   string
+	Boxes    int
+}
+
+// New lays p out, with a QR code for link unless link is empty. Resolve and
+// convert p's loads first to print them in a particular lifter's numbers.
+func New(p *db.Program, link string) (*Sheet, error) {
+	s := &Sheet{Program: p.Name, Author: p.SharedBy, Link: link}
+	if link != "" {
+		qr, err := qrcode.New(link, qrcode.Medium)
+		if err != nil {
+			return nil, fmt.Errorf("qr code: %w", err)
+		}
+		s.QR = qr.Bitmap()
+	}
+	for _, d := range p.Days {
+		page := Page{Day: d.Name}
+		for _, ex := range d.Exercises {
+			r := row(ex)
+			page.Columns = max(page.Columns, r.Boxes)
+			page.Rows = append(page.Rows, r)
+		}
+		s.Pages = append(s.Pages, page)
+	}
+	return s, nil
+}
+
+func row(ex db.Exercise) Row {
+	r := Row{Group: ex.Group, Exercise: ex.Name, Rest: ex.Rest}
+	n := ex.Sets
+	if len(ex.SetPrescriptions) > 0 {
+		n = len(ex.SetPrescriptions)
+		var reps, targets []string
+		for _, sp := range ex.SetPrescriptions {
+			reps = append(reps, render.SetReps(sp))
+			t := render.Target(sp.Intensity)
+			if t == "" {
+				t = "–"
+			}
+			targets = append(targets, t)
+		}
+		r.Reps = strings.Join(reps, " / ")
+		r.Target = strings.Join(targets, " / ")
+		if same(targets) {
+			r.Target = render.Target(ex.SetPrescriptions[0].Intensity)
+		}
+	} else {
+		r.Reps = ex.Reps
+		r.Target = render.Target(ex.Intensity)
+	}
+	r.Sets = strconv.Itoa(n)
+	r.Boxes = min(n, MaxColumns)
+	return r
+}
+
+func same(s []string) bool {
+	for _, v := range s[1:] {
+		if v != s[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+// Package render writes programs as text to read and paste: Markdown for
+// chats and forums that format it, and plain text for everywhere else.
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// Markdown renders p with a heading per day and a list item per exercise,
+// nesting the exercises of a group under it.
+func Markdown(p *db.Program) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", escape(p.Name))
+	if p.SharedBy != "" {
+		fmt.Fprintf(&b, "\n_Shared by %s_\n", escape(p.SharedBy))
+	}
+	for _, d := range p.Days {
+		fmt.Fprintf(&b, "\n## %s\n\n", escape(d.Name))
+		if len(d.Exercises) == 0 {
+			b.WriteString("_Rest day_\n")
+		}
+		walk(d, func(g *db.ExerciseGroup) {
+			fmt.Fprintf(&b, "- **%s**\n", escape(GroupLine(*g)))
+		}, func(ex db.Exercise, grouped bool) {
+			if grouped {
+				b.WriteString("  ")
+			}
+			fmt.Fprintf(&b, "- %s\n", escape(Line(ex)))
+		})
+	}
+	return b.String()
+}
+
+// Text renders p as plain text, indenting exercises under their day and
+// group.
+func Text(p *db.Program) string {
+	var b strings.Builder
+	b.WriteString(p.Name + "\n")
+	if p.SharedBy != "" {
+		b.WriteString("Shared by " + p.SharedBy + "\n")
+	}
+	for _, d := range p.Days {
+		fmt.Fprintf(&b, "\n%s\n", d.Name)
+		if len(d.Exercises) == 0 {
+			b.WriteString("  Rest day\n")
+		}
+		walk(d, func(g *db.ExerciseGroup) {
+			fmt.Fprintf(&b, "  %s\n", GroupLine(*g))
+		}, func(ex db.Exercise, grouped bool) {
+			indent := "  "
+			if grouped {
+				indent = "    "
+			}
+			b.WriteString(indent + Line(ex) + "\n")
+		})
+	}
+	return b.String()
+}
+
+// walk visits d's exercises in order, calling group before the first
+// exercise of each run that belongs to a group.
+func walk(d db.Day, group func(*db.ExerciseGroup), exercise func(ex db.Exercise, grouped bool)) {
+	var current *db.ExerciseGroup
+	for _, ex := range d.Exercises {
+		g := d.GroupFor(ex)
+		if g != nil && g != current {
+			group(g)
+		}
+		current = g
+		exercise(ex, g != nil)
+	}
+}
+
+// Line describes an exercise on one line, such as
+// "Bench Press — 4×8-10 @ RPE 8, rest 90s".
+func Line(ex db.Exercise) string {
+	var work string
+	if len(ex.SetPrescriptions) > 0 {
+		sets := make([]string, len(ex.SetPrescriptions))
+		for i, sp := range ex.SetPrescriptions {
+			sets[i] = SetReps(sp)
+			if t := Target(sp.Intensity); t != "" {
+				sets[i] += " @ " + t
+			}
+		}
+		work = strings.Join(sets, ", ")
+	} else {
+		work = fmt.Sprintf("%d×%s", ex.Sets, ex.Reps)
+		if t := Target(ex.Intensity); t != "" {
+			work += " @ " + t
+		}
+	}
+	line := ex.Name + " — " + work
+	if ex.Rest != "" {
+		line += ", rest " + ex.Rest
+	}
+	return line
+}
+
+var groupTypes = map[string]string{
+	db.GroupStraight: "Straight sets",
+	db.GroupSuperset: "Superset",
+	db.GroupGiantSet: "Giant set",
+	db.GroupCircuit:  "Circuit",
+	db.GroupEMOM:     "EMOM",
+	db.GroupAMRAP:    "AMRAP",
+}
+
+// GroupLine describes an exercise group, such as
+// "Superset A — 3 rounds, rest 2m between rounds".
+func GroupLine(g db.ExerciseGroup) string {
+	name, ok := groupTypes[g.Type]
+	if !ok {
+		name = g.Type
+	}
+	line := name + " " + g.Label
+	var parts []string
+	if g.Rounds > 0 {
+		parts = append(parts, fmt.Sprintf("%d rounds", g.Rounds))
+	}
+	if g.RestBetweenRounds != "" {
+		parts = append(parts, "rest "+g.RestBetweenRounds+" between rounds")
+	}
+	if g.TimeCap != "" {
+		parts = append(parts, "time cap "+g.TimeCap)
+	}
+	if len(parts) > 0 {
+		line += " — " + strings.Join(parts, ", ")
+	}
+	return line
+}
+
+// SetReps writes the reps of one prescribed set, marking warm-ups with a W
+// and AMRAP sets with a +.
+func SetReps(sp db.SetPrescription) string {
+	switch {
+	case sp.Type == db.SetWarmup:
+		return "W" + sp.Reps
+	case sp.Type == db.SetAMRAP && !strings.HasSuffix(sp.Reps, "+"):
+		return sp.Reps + "+"
+	}
+	return sp.Reps
+}
+
+// Target describes an intensity the way a lifter writes it down, such as
+// "75% TM (105 kg), RPE 8".
+func Target(in db.Intensity) string {
+	var parts []string
+	switch {
+	case in.Load != nil:
+		parts = append(parts, fmt.Sprintf("%g %s", *in.Load, in.LoadUnit))
+	case in.PercentTM != nil && in.Resolved != nil:
+		parts = append(parts, fmt.Sprintf("%g%% TM (%g %s)", *in.PercentTM, in.Resolved.Load, in.Resolved.LoadUnit))
+	case in.PercentTM != nil:
+		parts = append(parts, fmt.Sprintf("%g%% TM", *in.PercentTM))
+	}
+	switch {
+	case in.RPE != nil:
+		parts = append(parts, fmt.Sprintf("RPE %g", *in.RPE))
+	case in.RIR != nil:
+		parts = append(parts, fmt.Sprintf("%d RIR", *in.RIR))
+	}
+	if in.Tempo != "" {
+		parts = append(parts, "tempo "+in.Tempo)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// escape keeps names from being read as Markdown.
+var escape = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+).Replace
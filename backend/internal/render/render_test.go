@@ -0,0 +1,95 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+func program() *db.Program {
+	rpe, pct, load := 8.0, 75.0, 60.0
+	return &db.Program{
+		Name:     "Upper/Lower",
+		SharedBy: "jane_doe@example.com",
+		Days: []db.Day{
+			{
+				Name:   "Upper",
+				Groups: []db.ExerciseGroup{{Label: "A", Type: db.GroupSuperset, Rounds: 3, RestBetweenRounds: "2m"}},
+				Exercises: []db.Exercise{
+					{Name: "Bench Press", Sets: 4, Reps: "8-10", Rest: "90s", Intensity: db.Intensity{RPE: &rpe}},
+					{Name: "Chin-Up", Sets: 3, Reps: "AMRAP", Group: "A"},
+					{Name: "Dips", Sets: 3, Reps: "10", Group: "A"},
+					{Name: "Face Pull", Sets: 3, Reps: "15", Rest: "60s"},
+				},
+			},
+			{
+				Name: "Lower",
+				Exercises: []db.Exercise{
+					{Name: "Back Squat", Rest: "3m", SetPrescriptions: []db.SetPrescription{
+						{Type: db.SetWarmup, Reps: "5", Intensity: db.Intensity{Load: &load, LoadUnit: db.UnitKg}},
+						{Type: db.SetWorking, Reps: "5", Intensity: db.Intensity{PercentTM: &pct}},
+						{Type: db.SetAMRAP, Reps: "5", Intensity: db.Intensity{PercentTM: &pct}},
+					}},
+				},
+			},
+			{Name: "Sunday"},
+		},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	assert.Equal(t, `# Upper/Lower
+
+_Shared by jane\_doe@example.com_
+
+## Upper
+
+- Bench Press — 4×8-10 @ RPE 8, rest 90s
+- **Superset A — 3 rounds, rest 2m between rounds**
+  - Chin-Up — 3×AMRAP
+  - Dips — 3×10
+- Face Pull — 3×15, rest 60s
+
+## Lower
+
+- Back Squat — W5 @ 60 kg, 5 @ 75% TM, 5+ @ 75% TM, rest 3m
+
+## Sunday
+
+_Rest day_
+`, Markdown(program()))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, `Upper/Lower
+Shared by jane_doe@example.com
+
+Upper
+  Bench Press — 4×8-10 @ RPE 8, rest 90s
+  Superset A — 3 rounds, rest 2m between rounds
+    Chin-Up — 3×AMRAP
+    Dips — 3×10
+  Face Pull — 3×15, rest 60s
+
+Lower
+  Back Squat — W5 @ 60 kg, 5 @ 75% TM, 5+ @ 75% TM, rest 3m
+
+Sunday
+  Rest day
+`, Text(program()))
+}
+
+func TestTarget(t *testing.T) {
+	pct, rir := 80.0, 2
+	in := db.Intensity{PercentTM: &pct, RIR: &rir, Tempo: "3-1-1-0",
+		Resolved: &db.Resolved{Load: 120, LoadUnit: db.UnitKg, TrainingMax: 150}}
+	assert.Equal(t, "80% TM (120 kg), 2 RIR, tempo 3-1-1-0", Target(in))
+	assert.Empty(t, Target(db.Intensity{}))
+}
+
+func TestMarkdownEscapes(t *testing.T) {
+	p := &db.Program{Name: "*Best* [program]", Days: []db.Day{{Name: "#1", Exercises: []db.Exercise{{Name: "Squat_v2", Sets: 1, Reps: "5"}}}}}
+	assert.Equal(t, "# \\*Best\\* \\[program\\]\n\n## \\#1\n\n- Squat\\_v2 — 1×5\n", Markdown(p))
+}
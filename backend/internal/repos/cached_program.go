@@ -0,0 +1,180 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/cache"
+)
+
+// ProgramStore is a ProgramRepo that also manages the trash, as
+// GORMProgramRepo does.
+type ProgramStore interface {
+	ProgramRepo
+	TrashRepo
+}
+
+const programListKey = "programs:list"
+
+func programKey(id string) string { return "programs:" + id }
+
+// CachedProgramRepo is a read-through cache in front of a ProgramStore. Get
+// and List are served from Cache for up to TTL; every write through it,
+// including a restore from the trash, drops the entries it affects.
+// Programs are cached as JSON, so callers may modify what they get back.
+//
+// Inner should read from the primary database, as GORMProgramRepo.Primary
+// does: a read from a lagging replica right after an invalidation would
+// cache the old program for the whole TTL.
+type CachedProgramRepo struct {
+	Inner ProgramStore
+	Cache cache.Cache
+	TTL   time.Duration
+
+	// gen changes on every invalidation, so a read that raced with a write
+	// doesn't store what it read.
+	gen                  atomic.Uint64
+	hits, misses, errors atomic.Uint64
+}
+
+// NewCachedProgramRepo wraps inner with c.
+func NewCachedProgramRepo(inner ProgramStore, c cache.Cache, ttl time.Duration) *CachedProgramRepo {
+	return &CachedProgramRepo{Inner: inner, Cache: c, TTL: ttl}
+}
+
+// Stats reports cache hits, misses and errors so far.
+func (r *CachedProgramRepo) Stats() cache.Stats {
+	s := cache.Stats{Hits: r.hits.Load(), Misses: r.misses.Load(), Errors: r.errors.Load()}
+	if total := s.Hits + s.Misses; total > 0 {
+		s.HitRatio = float64(s.Hits) / float64(total)
+	}
+	return s
+}
+
+func (r *CachedProgramRepo) Get(ctx context.Context, id string) (*db.Program, error) {
+	var p db.Program
+	if r.lookup(ctx, programKey(id), &p) {
+		return &p, nil
+	}
+	gen := r.gen.Load()
+	got, err := r.Inner.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, gen, programKey(id), got)
+	return got, nil
+}
+
+func (r *CachedProgramRepo) List(ctx context.Context) ([]db.Program, error) {
+	var list []db.Program
+	if r.lookup(ctx, programListKey, &list) {
+		return list, nil
+	}
+	gen := r.gen.Load()
+	list, err := r.Inner.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, gen, programListKey, list)
+	return list, nil
+}
+
+func (r *CachedProgramRepo) Create(ctx context.Context, p *db.Program) (*db.Program, error) {
+	defer r.invalidate(ctx, programListKey)
+	return r.Inner.Create(ctx, p)
+}
+
+func (r *CachedProgramRepo) CreateMany(ctx context.Context, ps []*db.Program) ([]*db.Program, error) {
+	defer r.invalidate(ctx, programListKey)
+	return r.Inner.CreateMany(ctx, ps)
+}
+
+func (r *CachedProgramRepo) Update(ctx context.Context, p *db.Program) (*db.Program, error) {
+	defer r.invalidate(ctx, programKey(p.ID), programListKey)
+	return r.Inner.Update(ctx, p)
+}
+
+func (r *CachedProgramRepo) Delete(ctx context.Context, id string, version int) error {
+	defer r.invalidate(ctx, programKey(id), programListKey)
+	return r.Inner.Delete(ctx, id, version)
+}
+
+func (r *CachedProgramRepo) Fork(ctx context.Context, id string, sharedBy string) (*db.Program, error) {
+	defer r.invalidate(ctx, programListKey)
+	return r.Inner.Fork(ctx, id, sharedBy)
+}
+
+// ListTrash is not cached.
+func (r *CachedProgramRepo) ListTrash(ctx context.Context) ([]db.Program, error) {
+	return r.Inner.ListTrash(ctx)
+}
+
+func (r *CachedProgramRepo) Restore(ctx context.Context, id string) (*db.Program, error) {
+	defer r.invalidate(ctx, programKey(id), programListKey)
+	return r.Inner.Restore(ctx, id)
+}
+
+// Purge only removes programs already in the trash, which are never cached.
+func (r *CachedProgramRepo) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	return r.Inner.Purge(ctx, cutoff)
+}
+
+// lookup decodes the cached value under key into dst and counts the hit or
+// miss. Cache failures count as misses.
+func (r *CachedProgramRepo) lookup(ctx context.Context, key string, dst any) bool {
+	data, ok, err := r.Cache.Get(ctx, key)
+	if err == nil && ok {
+		err = json.Unmarshal(data, dst)
+		if err == nil {
+			r.hits.Add(1)
+			return true
+		}
+	}
+	if err != nil {
+		r.fail("get", key, err)
+	}
+	r.misses.Add(1)
+	return false
+}
+
+// store caches v under key unless an invalidation happened since gen. One
+// may also land between the check and the Set, after its Delete ran, so
+// gen is checked again afterwards and the entry dropped if it moved.
+func (r *CachedProgramRepo) store(ctx context.Context, gen uint64, key string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.fail("set", key, err)
+		return
+	}
+	if r.gen.Load() != gen {
+		return
+	}
+	if err := r.Cache.Set(ctx, key, data, r.TTL); err != nil {
+		r.fail("set", key, err)
+		return
+	}
+	if r.gen.Load() != gen {
+		if err := r.Cache.Delete(context.WithoutCancel(ctx), key); err != nil {
+			r.fail("delete", key, err)
+		}
+	}
+}
+
+// invalidate drops keys after a write, whether or not the write succeeded:
+// a failed write may still have changed the row, e.g. a commit whose reply
+// was lost.
+func (r *CachedProgramRepo) invalidate(ctx context.Context, keys ...string) {
+	r.gen.Add(1)
+	if err := r.Cache.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+		r.fail("delete", keys[0], err)
+	}
+}
+
+func (r *CachedProgramRepo) fail(op, key string, err error) {
+	r.errors.Add(1)
+	slog.Warn("program cache: "+op+" failed", "key", key, "err", err)
+}
@@ -0,0 +1,70 @@
+package repos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/cache"
+)
+
+// racingCache runs beforeSet just before each Set reaches the store.
+type racingCache struct {
+	cache.Cache
+	beforeSet func()
+}
+
+func (c *racingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.beforeSet()
+	return c.Cache.Set(ctx, key, value, ttl)
+}
+
+func TestStoreDropsEntryInvalidatedDuringSet(t *testing.T) {
+	ctx := context.Background()
+	c := &racingCache{Cache: cache.NewLRU(10), beforeSet: func() {}}
+	r := NewCachedProgramRepo(nil, c, time.Minute)
+
+	r.store(ctx, r.gen.Load(), programListKey, []string{"fresh"})
+	_, ok, _ := c.Get(ctx, programListKey)
+	assert.True(t, ok)
+
+	// a write invalidates after store checked gen but before its Set
+	c.beforeSet = func() { r.invalidate(ctx, programListKey) }
+	r.store(ctx, r.gen.Load(), programListKey, []string{"stale"})
+	_, ok, _ = c.Get(ctx, programListKey)
+	assert.False(t, ok, "the stale read must not stay cached")
+	assert.Zero(t, r.Stats().Errors)
+}
+
+func TestPrimaryReadsSkipLaggingReplica(t *testing.T) {
+	open := func(name string) *gorm.DB {
+		conn, err := gorm.Open(sqlite.Open("file:"+t.Name()+name+"?mode=memory&cache=shared"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.Migrate(conn))
+		return conn
+	}
+	primary, replica := open("primary"), open("replica")
+	require.NoError(t, primary.Use(dbresolver.Register(dbresolver.Config{Replicas: []gorm.Dialector{replica.Dialector}})))
+
+	// the replica has not caught up with this write yet
+	store := NewGORMProgramRepo(primary)
+	p, err := store.Create(context.Background(), &db.Program{Name: "New", SharedBy: "a@example.com",
+		Days: []db.Day{{Name: "A", Exercises: []db.Exercise{{Name: "Squat", Sets: 5, Reps: "5"}}}}})
+	require.NoError(t, err)
+	_, err = store.Get(context.Background(), p.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound, "plain reads go to the replica")
+
+	r := NewCachedProgramRepo(store.Primary(), cache.NewLRU(10), time.Minute)
+	got, err := r.Get(context.Background(), p.ID)
+	require.NoError(t, err)
+	if assert.Len(t, got.Days, 1) {
+		assert.Len(t, got.Days[0].Exercises, 1, "preloads read the primary too")
+	}
+}
@@ -0,0 +1,60 @@
+package repos
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"gorm.io/gorm"
+)
+
+// GORMPlateInventoryRepo implements PlateInventoryRepo using GORM.
+type GORMPlateInventoryRepo struct {
+	DB *gorm.DB
+}
+
+// NewGORMPlateInventoryRepo wires in a *gorm.DB instance.
+func NewGORMPlateInventoryRepo(dbConn *gorm.DB) *GORMPlateInventoryRepo {
+	return &GORMPlateInventoryRepo{DB: dbConn}
+}
+
+func (r *GORMPlateInventoryRepo) Create(ctx context.Context, inv *db.PlateInventory) (*db.PlateInventory, error) {
+	if inv.ID == "" {
+		inv.ID = uuid.NewString()
+	}
+	if err := r.DB.WithContext(ctx).Create(inv).Error; err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (r *GORMPlateInventoryRepo) Get(ctx context.Context, id string) (*db.PlateInventory, error) {
+	var inv db.PlateInventory
+	if err := r.DB.WithContext(ctx).First(&inv, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *GORMPlateInventoryRepo) List(ctx context.Context, owner string) ([]db.PlateInventory, error) {
+	var list []db.PlateInventory
+	if err := r.DB.WithContext(ctx).
+		Where("owner = ?", owner).
+		Order("created_at").
+		Find(&list).
+		Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *GORMPlateInventoryRepo) Delete(ctx context.Context, id string) error {
+	res := r.DB.WithContext(ctx).Delete(&db.PlateInventory{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
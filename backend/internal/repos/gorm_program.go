@@ -3,9 +3,11 @@ package repos
 import (
 	"context"
 	"github.com/google/uuid"
+	"time"
 
 	"github.com/iraunchy/dyel/backend/db"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 // GORMProgramRepo implements ProgramRepo using GORM.
@@ -18,7 +20,64 @@ func NewGORMProgramRepo(dbConn *gorm.DB) *GORMProgramRepo {
 	return &GORMProgramRepo{DB: dbConn}
 }
 
+// Primary returns a repo whose reads also go to the primary database
+// rather than a read replica, for callers that must not see a lagging copy.
+func (r *GORMProgramRepo) Primary() *GORMProgramRepo {
+	return &GORMProgramRepo{DB: r.DB.Clauses(dbresolver.Write).Session(&gorm.Session{})}
+}
+
 func (r *GORMProgramRepo) Create(ctx context.Context, p *db.Program) (*db.Program, error) {
+	tx := r.DB.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	if err := createTx(tx, p); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CreateMany inserts every program in one transaction: either all of them
+// are created or, on the first error, none are.
+func (r *GORMProgramRepo) CreateMany(ctx context.Context, ps []*db.Program) ([]*db.Program, error) {
+	tx := r.DB.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
+		return nil, err
+	}
+
+	for _, p := range ps {
+		if err := createTx(tx, p); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// createTx inserts a new program tree and its created event within tx.
+func createTx(tx *gorm.DB, p *db.Program) error {
+	prepareTree(p)
+	p.Version = 1
+
+	if err := tx.Create(p).Error; err != nil {
+		return err
+	}
+	return enqueueEvent(tx, db.EventProgramCreated, p)
+}
+
+// prepareTree fills in missing UUIDs, parent keys and set positions across
+// the program tree, and derives each exercise's Sets/Reps summary.
+func prepareTree(p *db.Program) {
 	if p.ID == "" {
 		p.ID = uuid.NewString()
 	}
@@ -30,21 +89,116 @@ func (r *GORMProgramRepo) Create(ctx context.Context, p *db.Program) (*db.Progra
 		}
 		day.ProgramID = p.ID
 
+		for gi := range day.Groups {
+			g := &day.Groups[gi]
+			if g.ID == "" {
+				g.ID = uuid.NewString()
+			}
+			g.DayID = day.ID
+		}
+
 		for ei := range day.Exercises {
 			ex := &day.Exercises[ei]
 			if ex.ID == "" {
 				ex.ID = uuid.NewString()
 			}
 			ex.DayID = day.ID
+
+			for si := range ex.SetPrescriptions {
+				set := &ex.SetPrescriptions[si]
+				if set.ID == "" {
+					set.ID = uuid.NewString()
+				}
+				set.ExerciseID = ex.ID
+				set.Position = si
+			}
+			ex.Summarize()
 		}
 	}
+}
+
+// preloadTree loads each program's days with their groups and exercises,
+// and each exercise's set prescriptions in order.
+func preloadTree(q *gorm.DB) *gorm.DB {
+	return q.Preload("Days.Groups").
+		Preload("Days.Exercises").
+		Preload("Days.Exercises.SetPrescriptions", func(q *gorm.DB) *gorm.DB {
+			return q.Order("position")
+		})
+}
 
+func (r *GORMProgramRepo) Get(ctx context.Context, id string) (*db.Program, error) {
+	var p db.Program
+	if err := preloadTree(r.DB.WithContext(ctx)).
+		First(&p, "id = ?", id).
+		Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *GORMProgramRepo) List(ctx context.Context) ([]db.Program, error) {
+	var list []db.Program
+	if err := preloadTree(r.DB.WithContext(ctx)).
+		Find(&list).
+		Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Each pages through programs by primary key, so every batch is a short
+// query and only one batch is held at a time.
+func (r *GORMProgramRepo) Each(ctx context.Context, batchSize int, fn func(*db.Program) error) error {
+	var batch []db.Program
+	return preloadTree(r.DB.WithContext(ctx)).
+		FindInBatches(&batch, batchSize, func(_ *gorm.DB, _ int) error {
+			for i := range batch {
+				if err := fn(&batch[i]); err != nil {
+					return err
+				}
+			}
+			return ctx.Err()
+		}).
+		Error
+}
+
+// Update replaces the program and its whole tree of days, groups, exercises
+// and set prescriptions, keeping only its creation time. When p.Version is
+// non-zero it must match the stored version or ErrVersionConflict is
+// returned; on success p.Version is the new, bumped version.
+func (r *GORMProgramRepo) Update(ctx context.Context, p *db.Program) (*db.Program, error) {
 	tx := r.DB.WithContext(ctx).Begin()
 	if err := tx.Error; err != nil {
 		return nil, err
 	}
 
-	if err := tx.Create(p).Error; err != nil {
+	version, err := bumpVersion(tx, p.ID, p.Version)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	p.Version = version
+
+	var stored db.Program
+	if err := tx.Select("created_at").First(&stored, "id = ?", p.ID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	p.CreatedAt = stored.CreatedAt
+
+	if err := deleteDays(tx, []string{p.ID}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	prepareTree(p)
+
+	if err := tx.Save(p).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := enqueueEvent(tx, db.EventProgramUpdated, p); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -55,21 +209,150 @@ func (r *GORMProgramRepo) Create(ctx context.Context, p *db.Program) (*db.Progra
 	return p, nil
 }
 
-func (r *GORMProgramRepo) Get(ctx context.Context, id string) (*db.Program, error) {
-	var p db.Program
-	if err := r.DB.WithContext(ctx).
-		Preload("Days.Exercises").
-		First(&p, "id = ?", id).
+// bumpVersion increments the stored version of program id, first checking it
+// equals expected unless expected is zero, and returns the new version.
+func bumpVersion(tx *gorm.DB, id string, expected int) (int, error) {
+	q := tx.Model(&db.Program{}).Where("id = ?", id)
+	if expected != 0 {
+		q = q.Where("version = ?", expected)
+	}
+	res := q.UpdateColumn("version", gorm.Expr("version + 1"))
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, missingOrConflict(tx, id)
+	}
+
+	var version int
+	if err := tx.Model(&db.Program{}).
+		Where("id = ?", id).
+		Select("version").
+		Scan(&version).
 		Error; err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// missingOrConflict explains why a versioned write matched no rows.
+func missingOrConflict(tx *gorm.DB, id string) error {
+	var n int64
+	if err := tx.Model(&db.Program{}).Where("id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrVersionConflict
+}
+
+// Delete moves the program to the trash. A non-zero version must match the
+// stored one, otherwise ErrVersionConflict is returned and nothing changes.
+func (r *GORMProgramRepo) Delete(ctx context.Context, id string, version int) error {
+	tx := r.DB.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+
+	q := tx.Where("id = ?", id)
+	if version != 0 {
+		q = q.Where("version = ?", version)
+	}
+	res := q.Delete(&db.Program{})
+	if res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 && version != 0 {
+		tx.Rollback()
+		return ErrVersionConflict
+	}
+
+	if res.RowsAffected > 0 {
+		if err := enqueueEvent(tx, db.EventProgramDeleted, map[string]any{"id": id}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// Fork copies the program identified by id, giving the copy and all of its
+// days, groups and exercises fresh IDs, and attributes it to sharedBy.
+func (r *GORMProgramRepo) Fork(ctx context.Context, id string, sharedBy string) (*db.Program, error) {
+	tx := r.DB.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
 		return nil, err
 	}
-	return &p, nil
+
+	var src db.Program
+	if err := preloadTree(tx).First(&src, "id = ?", id).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	fork := db.Program{Name: src.Name, SharedBy: sharedBy, Version: 1}
+	for _, d := range src.Days {
+		day := db.Day{Name: d.Name}
+		for _, g := range d.Groups {
+			day.Groups = append(day.Groups, db.ExerciseGroup{
+				Label:             g.Label,
+				Type:              g.Type,
+				Rounds:            g.Rounds,
+				RestBetweenRounds: g.RestBetweenRounds,
+				TimeCap:           g.TimeCap,
+			})
+		}
+		for _, ex := range d.Exercises {
+			var sets []db.SetPrescription
+			for _, set := range ex.SetPrescriptions {
+				sets = append(sets, db.SetPrescription{
+					Type:      set.Type,
+					Reps:      set.Reps,
+					Intensity: set.Intensity,
+				})
+			}
+			day.Exercises = append(day.Exercises, db.Exercise{
+				Name:             ex.Name,
+				Sets:             ex.Sets,
+				Reps:             ex.Reps,
+				Rest:             ex.Rest,
+				Intensity:        ex.Intensity,
+				Group:            ex.Group,
+				SetPrescriptions: sets,
+			})
+		}
+		fork.Days = append(fork.Days, day)
+	}
+	prepareTree(&fork)
+
+	if err := tx.Create(&fork).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	event := map[string]any{"source_id": src.ID, "program": &fork}
+	if err := enqueueEvent(tx, db.EventProgramForked, event); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &fork, nil
 }
 
-func (r *GORMProgramRepo) List(ctx context.Context) ([]db.Program, error) {
+// ListTrash returns the soft-deleted programs, most recently deleted first.
+func (r *GORMProgramRepo) ListTrash(ctx context.Context) ([]db.Program, error) {
 	var list []db.Program
-	if err := r.DB.WithContext(ctx).
-		Preload("Days.Exercises").
+	if err := preloadTree(r.DB.WithContext(ctx)).
+		Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
 		Find(&list).
 		Error; err != nil {
 		return nil, err
@@ -77,15 +360,87 @@ func (r *GORMProgramRepo) List(ctx context.Context) ([]db.Program, error) {
 	return list, nil
 }
 
-func (r *GORMProgramRepo) Update(ctx context.Context, p *db.Program) (*db.Program, error) {
-	if err := r.DB.WithContext(ctx).Save(p).Error; err != nil {
+// Restore takes a program out of the trash and bumps its version.
+func (r *GORMProgramRepo) Restore(ctx context.Context, id string) (*db.Program, error) {
+	tx := r.DB.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
 		return nil, err
 	}
-	return p, nil
+
+	res := tx.Unscoped().
+		Model(&db.Program{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		UpdateColumns(map[string]any{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		tx.Rollback()
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var p db.Program
+	if err := preloadTree(tx).First(&p, "id = ?", id).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := enqueueEvent(tx, db.EventProgramRestored, &p); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
-func (r *GORMProgramRepo) Delete(ctx context.Context, id string) error {
-	return r.DB.WithContext(ctx).
-		Delete(&db.Program{}, "id = ?", id).
-		Error
+// Purge permanently removes programs trashed before cutoff, together with
+// their days, groups and exercises, and returns how many programs went.
+func (r *GORMProgramRepo) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		if err := tx.Unscoped().
+			Model(&db.Program{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Pluck("id", &ids).
+			Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := deleteDays(tx, ids); err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", ids).Delete(&db.Program{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
+
+// deleteDays removes the days of the programs ids together with their
+// groups, exercises and set prescriptions.
+func deleteDays(tx *gorm.DB, ids []string) error {
+	days := tx.Model(&db.Day{}).Select("id").Where("program_id IN ?", ids)
+	exercises := tx.Model(&db.Exercise{}).Select("id").Where("day_id IN (?)", days)
+	if err := tx.Where("exercise_id IN (?)", exercises).Delete(&db.SetPrescription{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("day_id IN (?)", days).Delete(&db.Exercise{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("day_id IN (?)", days).Delete(&db.ExerciseGroup{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("program_id IN ?", ids).Delete(&db.Day{}).Error
 }
@@ -0,0 +1,296 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"gorm.io/gorm"
+)
+
+// Markers the database wraps around matched terms. Snippets are
+// HTML-escaped first and the markers then become <mark> tags, so stored
+// names can never inject markup.
+const (
+	markStart = "⟦"
+	markStop  = "⟧"
+)
+
+// maxSearchRows bounds the matching rows considered before ranking.
+const maxSearchRows = 1000
+
+// fieldWeights scale scores on backends without native field weighting;
+// Postgres applies the same ordering through setweight.
+var fieldWeights = map[string]float64{
+	db.SearchFieldProgramName:  1.0,
+	db.SearchFieldDayName:      0.6,
+	db.SearchFieldExerciseName: 0.6,
+	db.SearchFieldSharedBy:     0.4,
+}
+
+// searchRow is one matching field as returned by a backend query.
+type searchRow struct {
+	ProgramID string
+	Field     string
+	EntityID  string
+	Rank      float64
+	Snippet   string
+}
+
+// GORMSearchRepo implements SearchRepo with Postgres full-text search, or
+// SQLite FTS5 when available, falling back to LIKE matching otherwise.
+type GORMSearchRepo struct {
+	DB *gorm.DB
+}
+
+// NewGORMSearchRepo wires in a *gorm.DB instance.
+func NewGORMSearchRepo(dbConn *gorm.DB) *GORMSearchRepo {
+	return &GORMSearchRepo{DB: dbConn}
+}
+
+func (r *GORMSearchRepo) Search(ctx context.Context, query string, limit int) ([]SearchHit, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+
+	conn := r.DB.WithContext(ctx)
+	var (
+		rows []searchRow
+		err  error
+	)
+	switch {
+	case conn.Dialector.Name() == "postgres":
+		rows, err = searchPostgres(conn, query)
+	case db.HasSearchIndex(conn):
+		rows, err = searchFTS5(conn, terms)
+	default:
+		rows, err = searchLike(conn, terms)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	return rankHits(conn, rows, limit)
+}
+
+// pgSearchSQL queries every field with db.SearchConfig, the configuration
+// its search_vector column was built with.
+const pgSearchSQL = `
+WITH q AS (SELECT websearch_to_tsquery('` + db.SearchConfig + `', @query) AS q)
+SELECT p.id AS program_id, 'program.name' AS field, p.id AS entity_id,
+	ts_rank(p.search_vector, q.q) AS rank,
+	ts_headline('` + db.SearchConfig + `', p.name, q.q, @opts) AS snippet
+FROM programs p, q
+WHERE p.search_vector @@ q.q AND to_tsvector('` + db.SearchConfig + `', p.name) @@ q.q AND p.deleted_at IS NULL
+UNION ALL
+SELECT p.id, 'shared_by', p.id,
+	ts_rank(p.search_vector, q.q),
+	ts_headline('` + db.SearchConfig + `', p.shared_by, q.q, @opts)
+FROM programs p, q
+WHERE p.search_vector @@ q.q AND to_tsvector('` + db.SearchConfig + `', p.shared_by) @@ q.q AND p.deleted_at IS NULL
+UNION ALL
+SELECT d.program_id, 'day.name', d.id,
+	ts_rank(d.search_vector, q.q),
+	ts_headline('` + db.SearchConfig + `', d.name, q.q, @opts)
+FROM days d JOIN programs p ON p.id = d.program_id, q
+WHERE d.search_vector @@ q.q AND p.deleted_at IS NULL
+UNION ALL
+SELECT d.program_id, 'exercise.name', e.id,
+	ts_rank(e.search_vector, q.q),
+	ts_headline('` + db.SearchConfig + `', e.name, q.q, @opts)
+FROM exercises e JOIN days d ON d.id = e.day_id JOIN programs p ON p.id = d.program_id, q
+WHERE e.search_vector @@ q.q AND p.deleted_at IS NULL
+ORDER BY rank DESC
+LIMIT @max`
+
+func searchPostgres(conn *gorm.DB, query string) ([]searchRow, error) {
+	var rows []searchRow
+	err := conn.Raw(pgSearchSQL, map[string]any{
+		"query": query,
+		"opts":  "StartSel=" + markStart + ", StopSel=" + markStop + ", HighlightAll=true",
+		"max":   maxSearchRows,
+	}).Scan(&rows).Error
+	return rows, err
+}
+
+func searchFTS5(conn *gorm.DB, terms []string) ([]searchRow, error) {
+	// every term must match, as a prefix, in the same field; only body is indexed
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"*`
+	}
+
+	var rows []searchRow
+	err := conn.Raw(`
+SELECT search_index.program_id, search_index.field, search_index.entity_id,
+	-bm25(search_index) AS rank,
+	highlight(search_index, 3, ?, ?) AS snippet
+FROM search_index JOIN programs p ON p.id = search_index.program_id
+WHERE search_index MATCH ? AND p.deleted_at IS NULL
+ORDER BY rank DESC
+LIMIT ?`, markStart, markStop, strings.Join(quoted, " "), maxSearchRows).
+		Scan(&rows).Error
+	for i := range rows {
+		rows[i].Rank *= fieldWeights[rows[i].Field]
+	}
+	return rows, err
+}
+
+// searchLike is the portable fallback: a case-insensitive substring match
+// of every term, scored by field weight and marked up in Go.
+func searchLike(conn *gorm.DB, terms []string) ([]searchRow, error) {
+	sources := []struct {
+		field, sql, column string
+	}{
+		{db.SearchFieldProgramName, `SELECT p.id AS program_id, p.id AS entity_id, p.name AS snippet
+			FROM programs p`, "p.name"},
+		{db.SearchFieldSharedBy, `SELECT p.id AS program_id, p.id AS entity_id, p.shared_by AS snippet
+			FROM programs p`, "p.shared_by"},
+		{db.SearchFieldDayName, `SELECT d.program_id, d.id AS entity_id, d.name AS snippet
+			FROM days d JOIN programs p ON p.id = d.program_id`, "d.name"},
+		{db.SearchFieldExerciseName, `SELECT d.program_id, e.id AS entity_id, e.name AS snippet
+			FROM exercises e JOIN days d ON d.id = e.day_id JOIN programs p ON p.id = d.program_id`, "e.name"},
+	}
+
+	var out []searchRow
+	for _, src := range sources {
+		conds := make([]string, len(terms))
+		args := make([]any, len(terms))
+		for i, t := range terms {
+			conds[i] = "LOWER(" + src.column + `) LIKE ? ESCAPE '\'`
+			args[i] = "%" + escapeLike(strings.ToLower(t)) + "%"
+		}
+		conds = append(conds, "p.deleted_at IS NULL")
+		q := src.sql + " WHERE " + strings.Join(conds, " AND ") + fmt.Sprintf(" LIMIT %d", maxSearchRows)
+
+		var rows []searchRow
+		if err := conn.Raw(q, args...).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			row.Field = src.field
+			row.Rank = fieldWeights[src.field]
+			row.Snippet = markTerms(row.Snippet, terms)
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+// rankHits groups matching fields by program, scores each program by the sum
+// of its field ranks and loads the top limit programs.
+func rankHits(conn *gorm.DB, rows []searchRow, limit int) ([]SearchHit, error) {
+	byProgram := map[string]*SearchHit{}
+	var order []*SearchHit
+	for _, row := range rows {
+		hit := byProgram[row.ProgramID]
+		if hit == nil {
+			hit = &SearchHit{Program: db.Program{ID: row.ProgramID}}
+			byProgram[row.ProgramID] = hit
+			order = append(order, hit)
+		}
+		hit.Score += row.Rank
+		hit.Highlights = append(hit.Highlights, Highlight{
+			Field:    row.Field,
+			EntityID: row.EntityID,
+			Snippet:  renderSnippet(row.Snippet),
+		})
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return order[i].Score > order[j].Score })
+	if limit > 0 && len(order) > limit {
+		order = order[:limit]
+	}
+	if len(order) == 0 {
+		return []SearchHit{}, nil
+	}
+
+	ids := make([]string, len(order))
+	for i, hit := range order {
+		ids[i] = hit.Program.ID
+	}
+	var programs []db.Program
+	if err := preloadTree(conn).Where("id IN ?", ids).Find(&programs).Error; err != nil {
+		return nil, err
+	}
+	loaded := make(map[string]db.Program, len(programs))
+	for _, p := range programs {
+		loaded[p.ID] = p
+	}
+
+	hits := make([]SearchHit, 0, len(order))
+	for _, hit := range order {
+		p, ok := loaded[hit.Program.ID]
+		if !ok {
+			continue // deleted between the two queries
+		}
+		hit.Program = p
+		hits = append(hits, *hit)
+	}
+	return hits, nil
+}
+
+// searchTerms splits a query into lower-case words, ignoring punctuation
+// other than the characters that appear in e-mail addresses.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("@.-_", r)
+	})
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// markTerms wraps case-insensitive occurrences of terms in the match markers.
+func markTerms(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	marked := make([]bool, len(lower))
+	for _, t := range terms {
+		for i := 0; ; {
+			j := strings.Index(lower[i:], t)
+			if j < 0 {
+				break
+			}
+			for k := i + j; k < i+j+len(t); k++ {
+				marked[k] = true
+			}
+			i += j + len(t)
+		}
+	}
+	if len(lower) != len(text) {
+		// case folding changed byte offsets; skip marking rather than split runes
+		return text
+	}
+
+	var b strings.Builder
+	in := false
+	for i := 0; i < len(text); i++ {
+		if marked[i] != in {
+			if marked[i] {
+				b.WriteString(markStart)
+			} else {
+				b.WriteString(markStop)
+			}
+			in = marked[i]
+		}
+		b.WriteByte(text[i])
+	}
+	if in {
+		b.WriteString(markStop)
+	}
+	return b.String()
+}
+
+// renderSnippet escapes a marked snippet for HTML and turns the markers into
+// <mark> tags.
+func renderSnippet(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, markStart, "<mark>")
+	return strings.ReplaceAll(s, markStop, "</mark>")
+}
@@ -0,0 +1,49 @@
+package repos
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// TestPostgresSearchSQL checks the statement sent to Postgres without a
+// server: gorm's dry run builds it and stops short of executing it. The
+// query must use the configuration the search_vector columns are built
+// with, or stemmed terms stop matching. See gorm_search_postgres_test.go
+// for a run against a real server.
+func TestPostgresSearchSQL(t *testing.T) {
+	conn, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	require.NoError(t, err)
+
+	stmt := conn.Raw(pgSearchSQL, map[string]any{
+		"query": "bench press",
+		"opts":  "StartSel=<, StopSel=>",
+		"max":   maxSearchRows,
+	}).Scan(&[]searchRow{}).Statement
+
+	sql := stmt.SQL.String()
+	// each use of a named parameter is bound as its own placeholder
+	require.Len(t, stmt.Vars, 6)
+	assert.Equal(t, "bench press", stmt.Vars[0])
+	assert.Equal(t, maxSearchRows, stmt.Vars[5])
+	assert.Contains(t, sql, "LIMIT $6")
+	assert.NotRegexp(t, `@\w`, sql, "every named parameter is bound")
+
+	configs := regexp.MustCompile(`(?:to_tsvector|ts_headline|websearch_to_tsquery)\('(\w+)'`).
+		FindAllStringSubmatch(sql, -1)
+	assert.Len(t, configs, 7)
+	for _, m := range configs {
+		assert.Equal(t, db.SearchConfig, m[1], m[0])
+	}
+}
@@ -0,0 +1,67 @@
+package repos
+
+import (
+	"context"
+	"github.com/google/uuid"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+// GORMTrainingMaxRepo implements TrainingMaxRepo using GORM.
+type GORMTrainingMaxRepo struct {
+	DB *gorm.DB
+}
+
+// NewGORMTrainingMaxRepo wires in a *gorm.DB instance.
+func NewGORMTrainingMaxRepo(dbConn *gorm.DB) *GORMTrainingMaxRepo {
+	return &GORMTrainingMaxRepo{DB: dbConn}
+}
+
+func (r *GORMTrainingMaxRepo) List(ctx context.Context, userID string) ([]db.TrainingMax, error) {
+	var list []db.TrainingMax
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("exercise").
+		Find(&list).
+		Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Upsert stores m under its normalised exercise name and returns the row
+// as saved.
+func (r *GORMTrainingMaxRepo) Upsert(ctx context.Context, m *db.TrainingMax) (*db.TrainingMax, error) {
+	m.Exercise = db.ExerciseKey(m.Exercise)
+	if m.ID == "" {
+		m.ID = uuid.NewString()
+	}
+
+	conn := r.DB.WithContext(ctx)
+	if err := conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "exercise"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "unit", "updated_at"}),
+	}).Create(m).Error; err != nil {
+		return nil, err
+	}
+
+	var saved db.TrainingMax
+	if err := conn.Clauses(dbresolver.Write).First(&saved, "user_id = ? AND exercise = ?", m.UserID, m.Exercise).Error; err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (r *GORMTrainingMaxRepo) Delete(ctx context.Context, id string) error {
+	res := r.DB.WithContext(ctx).Delete(&db.TrainingMax{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/google/uuid"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"gorm.io/gorm"
+)
+
+// GORMWebhookRepo implements WebhookRepo using GORM.
+type GORMWebhookRepo struct {
+	DB *gorm.DB
+}
+
+// NewGORMWebhookRepo wires in a *gorm.DB instance.
+func NewGORMWebhookRepo(dbConn *gorm.DB) *GORMWebhookRepo {
+	return &GORMWebhookRepo{DB: dbConn}
+}
+
+// Create stores a new endpoint, generating an ID and a signing secret when
+// none is supplied.
+func (r *GORMWebhookRepo) Create(ctx context.Context, e *db.WebhookEndpoint) (*db.WebhookEndpoint, error) {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.Secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		e.Secret = hex.EncodeToString(buf)
+	}
+	e.Active = true
+
+	if err := r.DB.WithContext(ctx).Create(e).Error; err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *GORMWebhookRepo) List(ctx context.Context) ([]db.WebhookEndpoint, error) {
+	var list []db.WebhookEndpoint
+	if err := r.DB.WithContext(ctx).
+		Order("created_at").
+		Find(&list).
+		Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Delete removes the endpoint together with any deliveries still queued for it.
+func (r *GORMWebhookRepo) Delete(ctx context.Context, id string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&db.WebhookDelivery{}, "endpoint_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&db.WebhookEndpoint{}, "id = ?", id).Error
+	})
+}
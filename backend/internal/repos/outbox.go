@@ -0,0 +1,61 @@
+package repos
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iraunchy/dyel/backend/db"
+	"gorm.io/gorm"
+)
+
+// EventEnvelope is the JSON body delivered to webhook endpoints.
+type EventEnvelope struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// enqueueEvent writes one outbox row per active endpoint subscribed to event.
+// It must be called with the transaction that performs the change, so the
+// event is persisted if and only if the change commits.
+func enqueueEvent(tx *gorm.DB, event string, data any) error {
+	var endpoints []db.WebhookEndpoint
+	if err := tx.Where("active = ?", true).Find(&endpoints).Error; err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var payload []byte
+	var rows []db.WebhookDelivery
+	for _, ep := range endpoints {
+		if !ep.Subscribes(event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(EventEnvelope{
+				ID:         uuid.NewString(),
+				Event:      event,
+				OccurredAt: now,
+				Data:       data,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		rows = append(rows, db.WebhookDelivery{
+			ID:            uuid.NewString(),
+			EndpointID:    ep.ID,
+			Event:         event,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Create(&rows).Error
+}
@@ -0,0 +1,15 @@
+package repos
+
+import (
+	"context"
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// PlateInventoryRepo stores the plate inventories of users and gyms.
+type PlateInventoryRepo interface {
+	Create(ctx context.Context, inv *db.PlateInventory) (*db.PlateInventory, error)
+	Get(ctx context.Context, id string) (*db.PlateInventory, error)
+	// List returns the inventories belonging to owner.
+	List(ctx context.Context, owner string) ([]db.PlateInventory, error)
+	Delete(ctx context.Context, id string) error
+}
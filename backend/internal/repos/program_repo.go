@@ -2,13 +2,37 @@ package repos
 
 import (
 	"context"
+	"errors"
 	"github.com/iraunchy/dyel/backend/db"
+	"time"
 )
 
+// ErrVersionConflict is returned by versioned writes when the stored program
+// has changed since the caller read it.
+var ErrVersionConflict = errors.New("program was modified by someone else")
+
 type ProgramRepo interface {
 	Create(ctx context.Context, p *db.Program) (*db.Program, error)
+	CreateMany(ctx context.Context, ps []*db.Program) ([]*db.Program, error)
 	Get(ctx context.Context, id string) (*db.Program, error)
 	List(ctx context.Context) ([]db.Program, error)
 	Update(ctx context.Context, p *db.Program) (*db.Program, error)
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string, version int) error
+	Fork(ctx context.Context, id string, sharedBy string) (*db.Program, error)
+}
+
+// ProgramExporter streams the whole program library without holding it in
+// memory.
+type ProgramExporter interface {
+	// Each calls fn with every program that isn't in the trash, with its
+	// full tree, reading batchSize programs at a time in ID order. It stops
+	// at the first error from fn or the database, including ctx ending.
+	Each(ctx context.Context, batchSize int, fn func(*db.Program) error) error
+}
+
+// TrashRepo manages soft-deleted programs.
+type TrashRepo interface {
+	ListTrash(ctx context.Context) ([]db.Program, error)
+	Restore(ctx context.Context, id string) (*db.Program, error)
+	Purge(ctx context.Context, cutoff time.Time) (int64, error)
 }
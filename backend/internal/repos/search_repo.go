@@ -0,0 +1,26 @@
+package repos
+
+import (
+	"context"
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// Highlight is one field of a program that matched the query. Snippet is
+// HTML-escaped text with the matching terms wrapped in <mark>…</mark>.
+type Highlight struct {
+	Field    string `json:"field"`
+	EntityID string `json:"entity_id"`
+	Snippet  string `json:"snippet"`
+}
+
+// SearchHit is a matching program with its relevance score and the fields
+// that matched, best first.
+type SearchHit struct {
+	Program    db.Program  `json:"program"`
+	Score      float64     `json:"score"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+type SearchRepo interface {
+	Search(ctx context.Context, query string, limit int) ([]SearchHit, error)
+}
@@ -0,0 +1,16 @@
+package repos
+
+import (
+	"context"
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+// TrainingMaxRepo stores each lifter's training maxes.
+type TrainingMaxRepo interface {
+	// List returns the training maxes recorded for userID.
+	List(ctx context.Context, userID string) ([]db.TrainingMax, error)
+	// Upsert records m, replacing any previous max for the same user and
+	// exercise.
+	Upsert(ctx context.Context, m *db.TrainingMax) (*db.TrainingMax, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,12 @@
+package repos
+
+import (
+	"context"
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+type WebhookRepo interface {
+	Create(ctx context.Context, e *db.WebhookEndpoint) (*db.WebhookEndpoint, error)
+	List(ctx context.Context) ([]db.WebhookEndpoint, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,215 @@
+// Package seed generates believable demo programs for local development,
+// frontend demos and load tests. The same Options always generate the same
+// programs.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+)
+
+// DefaultPrograms is how many programs are generated when Options.Programs
+// is zero.
+const DefaultPrograms = 20
+
+// createBatch is how many programs go into one CreateMany transaction.
+const createBatch = 100
+
+// Options sizes a generated data set.
+type Options struct {
+	// Seed makes the output reproducible.
+	Seed uint64
+	// Programs is how many programs to generate; DefaultPrograms when zero.
+	Programs int
+	// Templates restricts generation to these template slugs; all of them
+	// when empty.
+	Templates []string
+	// History edits some programs after creating them and moves a few to
+	// the trash, so versions, ETags, webhooks and the trash have something
+	// to show.
+	History bool
+}
+
+// Summary counts what Run wrote.
+type Summary struct {
+	Created int
+	Updated int
+	Trashed int
+}
+
+// Generate returns opts.Programs programs spread evenly over the chosen
+// templates, with exercises, sets, reps and rest picked by opts.Seed.
+func Generate(opts Options) ([]*db.Program, error) {
+	chosen, err := pick(opts.Templates)
+	if err != nil {
+		return nil, err
+	}
+	n := opts.Programs
+	if n == 0 {
+		n = DefaultPrograms
+	}
+	rng := rand.New(rand.NewPCG(opts.Seed, 1))
+	out := make([]*db.Program, n)
+	for i := range out {
+		out[i] = chosen[i%len(chosen)].build(rng, i+1)
+	}
+	return out, nil
+}
+
+// Run generates the programs and creates them through repo, then adds
+// history when asked.
+func Run(ctx context.Context, repo repos.ProgramRepo, opts Options) (Summary, error) {
+	var sum Summary
+	programs, err := Generate(opts)
+	if err != nil {
+		return sum, err
+	}
+	for start := 0; start < len(programs); start += createBatch {
+		batch := programs[start:min(start+createBatch, len(programs))]
+		if _, err := repo.CreateMany(ctx, batch); err != nil {
+			return sum, err
+		}
+		sum.Created += len(batch)
+	}
+	if !opts.History {
+		return sum, nil
+	}
+
+	// A separate stream keeps the programs themselves the same with or
+	// without history.
+	rng := rand.New(rand.NewPCG(opts.Seed, 2))
+	for _, p := range programs {
+		if rng.IntN(3) == 0 {
+			for range 1 + rng.IntN(3) {
+				revise(rng, p)
+				if _, err := repo.Update(ctx, p); err != nil {
+					return sum, err
+				}
+				sum.Updated++
+			}
+		}
+		if rng.IntN(20) == 0 {
+			if err := repo.Delete(ctx, p.ID, 0); err != nil {
+				return sum, err
+			}
+			sum.Trashed++
+		}
+	}
+	return sum, nil
+}
+
+// revise makes the kind of edit a lifter makes between blocks: more sets,
+// a different rep range or rest on one exercise.
+func revise(rng *rand.Rand, p *db.Program) {
+	if len(p.Days) == 0 {
+		return
+	}
+	day := &p.Days[rng.IntN(len(p.Days))]
+	if len(day.Exercises) == 0 {
+		return
+	}
+	ex := &day.Exercises[rng.IntN(len(day.Exercises))]
+	s := schemes[roleOf(ex)]
+	switch rng.IntN(3) {
+	case 0:
+		ex.Sets = min(ex.Sets+1, 6)
+	case 1:
+		ex.Reps = oneOf(rng, s.reps)
+	default:
+		ex.Rest = oneOf(rng, s.rest)
+	}
+}
+
+// Templates lists the template slugs Generate understands.
+func Templates() []string {
+	slugs := make([]string, len(templates))
+	for i, t := range templates {
+		slugs[i] = t.slug
+	}
+	return slugs
+}
+
+func pick(slugs []string) ([]template, error) {
+	if len(slugs) == 0 {
+		return templates, nil
+	}
+	var out []template
+	for _, s := range slugs {
+		i := indexOf(s)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown template %q; want one of %s", s, strings.Join(Templates(), ", "))
+		}
+		out = append(out, templates[i])
+	}
+	return out, nil
+}
+
+func indexOf(slug string) int {
+	for i, t := range templates {
+		if t.slug == slug {
+			return i
+		}
+	}
+	return -1
+}
+
+// build fills in one program from the template.
+func (t template) build(rng *rand.Rand, n int) *db.Program {
+	p := &db.Program{
+		Name:     fmt.Sprintf("%s %s #%d", oneOf(rng, t.styles), t.name, n),
+		SharedBy: oneOf(rng, authors) + "@example.com",
+	}
+	for _, d := range t.variant(rng) {
+		day := db.Day{Name: d.name}
+		for _, s := range d.slots {
+			day.Exercises = append(day.Exercises, s.exercise(rng))
+		}
+		p.Days = append(p.Days, day)
+	}
+	return p
+}
+
+// exercise picks a movement from the slot's pool and a scheme for its role.
+func (s slot) exercise(rng *rand.Rand) db.Exercise {
+	sc := schemes[s.role]
+	ex := db.Exercise{
+		Name: oneOf(rng, s.pool),
+		Sets: sc.sets[rng.IntN(len(sc.sets))],
+		Reps: oneOf(rng, sc.reps),
+		Rest: oneOf(rng, sc.rest),
+	}
+	if s.fixed != nil {
+		ex.Sets, ex.Reps = s.fixed.sets, s.fixed.reps
+		return ex
+	}
+	if len(sc.rpe) > 0 && rng.IntN(2) == 0 {
+		rpe := sc.rpe[rng.IntN(len(sc.rpe))]
+		ex.RPE = &rpe
+	}
+	return ex
+}
+
+// roleOf finds the role an exercise was generated for, by name.
+func roleOf(ex *db.Exercise) role {
+	for _, t := range templates {
+		for _, d := range t.days {
+			for _, s := range d.slots {
+				for _, name := range s.pool {
+					if name == ex.Name {
+						return s.role
+					}
+				}
+			}
+		}
+	}
+	return accessory
+}
+
+func oneOf(rng *rand.Rand, from []string) string {
+	return from[rng.IntN(len(from))]
+}
@@ -0,0 +1,87 @@
+package seed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+)
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	a, err := Generate(Options{Seed: 7, Programs: 40})
+	require.NoError(t, err)
+	b, err := Generate(Options{Seed: 7, Programs: 40})
+	require.NoError(t, err)
+	c, err := Generate(Options{Seed: 8, Programs: 40})
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 40)
+}
+
+func TestGenerateMakesValidPrograms(t *testing.T) {
+	programs, err := Generate(Options{Seed: 1, Programs: 200})
+	require.NoError(t, err)
+	for _, p := range programs {
+		assert.NotEmpty(t, p.Name)
+		assert.NotEmpty(t, p.SharedBy)
+		assert.NotEmpty(t, p.Days, p.Name)
+		for _, d := range p.Days {
+			assert.NoError(t, d.ValidateGroups())
+			seen := map[string]bool{}
+			for _, ex := range d.Exercises {
+				assert.False(t, seen[ex.Name], "%s repeats %s on %s", p.Name, ex.Name, d.Name)
+				seen[ex.Name] = true
+				assert.Positive(t, ex.Sets)
+				assert.NotEmpty(t, ex.Reps)
+				assert.NotEmpty(t, ex.Rest)
+				assert.NoError(t, ex.Intensity.Validate())
+			}
+		}
+	}
+
+	fives, err := Generate(Options{Programs: 3, Templates: []string{"5x5"}})
+	require.NoError(t, err)
+	for _, p := range fives {
+		assert.Equal(t, "Back Squat", p.Days[0].Exercises[0].Name)
+		assert.Equal(t, 5, p.Days[0].Exercises[0].Sets)
+	}
+
+	_, err = Generate(Options{Templates: []string{"bro-split"}})
+	assert.ErrorContains(t, err, `unknown template "bro-split"`)
+}
+
+func TestRun(t *testing.T) {
+	dbConn, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(dbConn))
+	repo := repos.NewGORMProgramRepo(dbConn)
+	ctx := context.Background()
+
+	sum, err := Run(ctx, repo, Options{Seed: 3, Programs: 150, History: true})
+	require.NoError(t, err)
+	assert.Equal(t, 150, sum.Created)
+	assert.Positive(t, sum.Updated)
+	assert.Positive(t, sum.Trashed)
+
+	live, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, live, 150-sum.Trashed)
+	versions := 0
+	for _, p := range live {
+		versions += p.Version - 1
+	}
+	assert.LessOrEqual(t, versions, sum.Updated)
+	assert.Positive(t, versions)
+
+	trashed, err := repo.ListTrash(ctx)
+	require.NoError(t, err)
+	assert.Len(t, trashed, sum.Trashed)
+}
@@ -0,0 +1,218 @@
+package seed
+
+import "math/rand/v2"
+
+// role decides the sets, reps and rest of a slot.
+type role int
+
+const (
+	primary role = iota
+	secondary
+	accessory
+)
+
+// scheme is the range of prescriptions for a role.
+type scheme struct {
+	sets []int
+	reps []string
+	rest []string
+	rpe  []float64
+}
+
+var schemes = map[role]scheme{
+	primary: {
+		sets: []int{3, 4, 5},
+		reps: []string{"3-5", "5", "4-6", "5-8"},
+		rest: []string{"2m", "3m", "3m"},
+		rpe:  []float64{7, 7.5, 8, 8.5},
+	},
+	secondary: {
+		sets: []int{3, 4},
+		reps: []string{"6-8", "8-10", "8-12"},
+		rest: []string{"90s", "2m"},
+		rpe:  []float64{7, 8},
+	},
+	accessory: {
+		sets: []int{2, 3, 3, 4},
+		reps: []string{"10-12", "12-15", "15-20"},
+		rest: []string{"45s", "60s", "90s"},
+	},
+}
+
+// fixed pins the sets and reps of a slot, as in 5x5.
+type fixed struct {
+	sets int
+	reps string
+}
+
+// slot is one exercise of a day: any movement from pool, done as role.
+type slot struct {
+	role  role
+	pool  []string
+	fixed *fixed
+}
+
+type dayPlan struct {
+	name  string
+	slots []slot
+}
+
+// template is a classic split. A template with repeats > 1 may run its days
+// up to that many times a week, as "Push A", "Push B" and so on.
+type template struct {
+	slug    string
+	name    string
+	styles  []string
+	days    []dayPlan
+	repeats int
+}
+
+// variant returns the days of one program built from t.
+func (t template) variant(rng *rand.Rand) []dayPlan {
+	times := 1
+	if t.repeats > 1 {
+		times = 1 + rng.IntN(t.repeats)
+	}
+	if times == 1 {
+		return t.days
+	}
+	var out []dayPlan
+	for i := range times {
+		for _, d := range t.days {
+			out = append(out, dayPlan{name: d.name + " " + string(rune('A'+i)), slots: d.slots})
+		}
+	}
+	return out
+}
+
+// Movement pools. Pools used in the same day don't overlap, so a day never
+// repeats an exercise.
+var (
+	horizontalPress = []string{"Bench Press", "Incline Bench Press", "Dumbbell Bench Press", "Close-Grip Bench Press"}
+	verticalPress   = []string{"Overhead Press", "Seated Dumbbell Press", "Push Press"}
+	horizontalPull  = []string{"Barbell Row", "Pendlay Row", "Chest-Supported Row", "Seated Cable Row"}
+	verticalPull    = []string{"Pull-Up", "Chin-Up", "Lat Pulldown"}
+	squat           = []string{"Back Squat", "Front Squat", "Safety Bar Squat"}
+	hinge           = []string{"Deadlift", "Romanian Deadlift", "Trap Bar Deadlift"}
+	singleLeg       = []string{"Bulgarian Split Squat", "Walking Lunge", "Leg Press", "Step-Up"}
+	hamstrings      = []string{"Lying Leg Curl", "Seated Leg Curl", "Nordic Curl", "Glute-Ham Raise"}
+	calves          = []string{"Standing Calf Raise", "Seated Calf Raise"}
+	chest           = []string{"Cable Fly", "Pec Deck", "Dumbbell Fly", "Push-Up"}
+	shoulders       = []string{"Lateral Raise", "Cable Lateral Raise", "Rear Delt Fly"}
+	rearDelts       = []string{"Face Pull", "Reverse Pec Deck", "Band Pull-Apart"}
+	triceps         = []string{"Triceps Pushdown", "Skull Crusher", "Overhead Triceps Extension", "Dips"}
+	biceps          = []string{"Barbell Curl", "Hammer Curl", "Incline Dumbbell Curl", "Cable Curl"}
+	core            = []string{"Plank", "Hanging Leg Raise", "Ab Wheel Rollout", "Pallof Press"}
+)
+
+var authors = []string{
+	"alex", "sam", "jordan", "taylor", "morgan", "casey", "riley", "jamie",
+	"avery", "quinn", "rowan", "sky", "devon", "kai", "noor", "emeka",
+}
+
+var templates = []template{
+	{
+		slug:    "ppl",
+		name:    "Push Pull Legs",
+		styles:  []string{"Classic", "Hypertrophy", "Intermediate", "Volume"},
+		repeats: 2,
+		days: []dayPlan{
+			{"Push", []slot{
+				{role: primary, pool: horizontalPress},
+				{role: secondary, pool: verticalPress},
+				{role: accessory, pool: chest},
+				{role: accessory, pool: shoulders},
+				{role: accessory, pool: triceps},
+			}},
+			{"Pull", []slot{
+				{role: primary, pool: horizontalPull},
+				{role: secondary, pool: verticalPull},
+				{role: accessory, pool: rearDelts},
+				{role: accessory, pool: biceps},
+			}},
+			{"Legs", []slot{
+				{role: primary, pool: squat},
+				{role: secondary, pool: hinge},
+				{role: accessory, pool: singleLeg},
+				{role: accessory, pool: hamstrings},
+				{role: accessory, pool: calves},
+			}},
+		},
+	},
+	{
+		slug:   "upper-lower",
+		name:   "Upper/Lower",
+		styles: []string{"Classic", "Power", "Hypertrophy", "Intermediate"},
+		days: []dayPlan{
+			{"Upper A", []slot{
+				{role: primary, pool: horizontalPress},
+				{role: secondary, pool: horizontalPull},
+				{role: secondary, pool: verticalPress},
+				{role: accessory, pool: verticalPull},
+				{role: accessory, pool: triceps},
+			}},
+			{"Lower A", []slot{
+				{role: primary, pool: squat},
+				{role: secondary, pool: hinge},
+				{role: accessory, pool: hamstrings},
+				{role: accessory, pool: calves},
+			}},
+			{"Upper B", []slot{
+				{role: primary, pool: verticalPress},
+				{role: secondary, pool: verticalPull},
+				{role: secondary, pool: horizontalPress},
+				{role: accessory, pool: horizontalPull},
+				{role: accessory, pool: biceps},
+			}},
+			{"Lower B", []slot{
+				{role: primary, pool: hinge},
+				{role: secondary, pool: squat},
+				{role: accessory, pool: singleLeg},
+				{role: accessory, pool: core},
+			}},
+		},
+	},
+	{
+		slug:   "5x5",
+		name:   "5x5",
+		styles: []string{"Classic", "Beginner", "Linear"},
+		days: []dayPlan{
+			{"Workout A", []slot{
+				{role: primary, pool: []string{"Back Squat"}, fixed: &fixed{5, "5"}},
+				{role: primary, pool: []string{"Bench Press"}, fixed: &fixed{5, "5"}},
+				{role: primary, pool: []string{"Barbell Row"}, fixed: &fixed{5, "5"}},
+			}},
+			{"Workout B", []slot{
+				{role: primary, pool: []string{"Back Squat"}, fixed: &fixed{5, "5"}},
+				{role: primary, pool: []string{"Overhead Press"}, fixed: &fixed{5, "5"}},
+				{role: primary, pool: []string{"Deadlift"}, fixed: &fixed{1, "5"}},
+			}},
+		},
+	},
+	{
+		slug:   "full-body",
+		name:   "Full Body",
+		styles: []string{"Beginner", "Minimalist", "Strength", "Busy Week"},
+		days: []dayPlan{
+			{"Day 1", []slot{
+				{role: primary, pool: squat},
+				{role: secondary, pool: horizontalPress},
+				{role: secondary, pool: horizontalPull},
+				{role: accessory, pool: core},
+			}},
+			{"Day 2", []slot{
+				{role: primary, pool: hinge},
+				{role: secondary, pool: verticalPress},
+				{role: secondary, pool: verticalPull},
+				{role: accessory, pool: singleLeg},
+			}},
+			{"Day 3", []slot{
+				{role: primary, pool: horizontalPress},
+				{role: secondary, pool: singleLeg},
+				{role: secondary, pool: horizontalPull},
+				{role: accessory, pool: biceps},
+				{role: accessory, pool: triceps},
+			}},
+		},
+	},
+}
@@ -0,0 +1,104 @@
+// Package session lays a Day out set by set, the way it is trained.
+package session
+
+import (
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/plates"
+)
+
+// Set is one set to perform. Load is in the inventory's unit; Plates shows
+// how to load the bar for it.
+type Set struct {
+	Number   int               `json:"number"`
+	Type     string            `json:"type"`
+	Reps     string            `json:"reps"`
+	Load     *float64          `json:"load"`
+	LoadUnit string            `json:"load_unit"`
+	RPE      *float64          `json:"rpe"`
+	RIR      *int              `json:"rir"`
+	Tempo    string            `json:"tempo"`
+	Plates   *plates.Breakdown `json:"plates"`
+}
+
+// Exercise is an exercise expanded into its sets.
+type Exercise struct {
+	ExerciseID string `json:"exercise_id"`
+	Name       string `json:"name"`
+	Group      string `json:"group"`
+	Rest       string `json:"rest"`
+	Sets       []Set  `json:"sets"`
+}
+
+// Day is the session view of a db.Day.
+type Day struct {
+	DayID     string             `json:"day_id"`
+	Name      string             `json:"name"`
+	Inventory db.PlateInventory  `json:"inventory"`
+	Groups    []db.ExerciseGroup `json:"groups"`
+	Exercises []Exercise         `json:"exercises"`
+}
+
+// Build expands every exercise of d into its sets: its set prescriptions
+// when it has them, otherwise Sets identical working sets. Loads, absolute
+// or resolved from a training max, are converted to inv's unit and broken
+// down into the plates inv has, once per distinct load.
+func Build(d db.Day, inv db.PlateInventory) Day {
+	day := Day{
+		DayID:     d.ID,
+		Name:      d.Name,
+		Inventory: inv,
+		Groups:    d.Groups,
+		Exercises: make([]Exercise, 0, len(d.Exercises)),
+	}
+	if day.Groups == nil {
+		day.Groups = []db.ExerciseGroup{}
+	}
+
+	breakdowns := map[float64]*plates.Breakdown{}
+	for _, ex := range d.Exercises {
+		out := Exercise{
+			ExerciseID: ex.ID,
+			Name:       ex.Name,
+			Group:      ex.Group,
+			Rest:       ex.Rest,
+			Sets:       []Set{},
+		}
+		if len(ex.SetPrescriptions) > 0 {
+			for _, sp := range ex.SetPrescriptions {
+				out.Sets = append(out.Sets, buildSet(len(out.Sets)+1, sp.Type, sp.Reps, sp.Intensity, inv, breakdowns))
+			}
+		} else {
+			for n := 1; n <= ex.Sets; n++ {
+				out.Sets = append(out.Sets, buildSet(n, db.SetWorking, ex.Reps, ex.Intensity, inv, breakdowns))
+			}
+		}
+		day.Exercises = append(day.Exercises, out)
+	}
+	return day
+}
+
+// buildSet lays out one set, reusing the breakdowns already worked out for
+// its load.
+func buildSet(n int, typ, reps string, in db.Intensity, inv db.PlateInventory, breakdowns map[float64]*plates.Breakdown) Set {
+	set := Set{Number: n, Type: typ, Reps: reps, RPE: in.RPE, RIR: in.RIR, Tempo: in.Tempo}
+
+	var load float64
+	switch {
+	case in.Load != nil && in.LoadUnit != "":
+		load = db.ConvertLoad(*in.Load, in.LoadUnit, inv.Unit)
+	case in.Resolved != nil:
+		load = db.ConvertLoad(in.Resolved.Load, in.Resolved.LoadUnit, inv.Unit)
+	default:
+		return set
+	}
+	b, ok := breakdowns[load]
+	if !ok {
+		calc := plates.Calculate(load, inv)
+		b = &calc
+		breakdowns[load] = b
+	}
+	set.Load = &load
+	set.LoadUnit = inv.Unit
+	set.Plates = b
+	return set
+}
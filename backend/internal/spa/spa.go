@@ -0,0 +1,190 @@
+// Package spa serves a built single-page app, falling back to index.html
+// for client-side routes.
+package spa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control values. Vite fingerprints everything under assets/, so
+// those never change; index.html must be revalidated to pick up new builds.
+const (
+	cacheImmutable = "public, max-age=31536000, immutable"
+	cacheIndex     = "no-cache"
+	cacheDefault   = "public, max-age=3600"
+)
+
+// minGzipSize is the smallest file worth compressing on the fly.
+const minGzipSize = 1024
+
+// Server serves the files of FS.
+type Server struct {
+	FS fs.FS
+	// Reserved path prefixes, such as "/api/", are never answered with
+	// index.html.
+	Reserved []string
+
+	mu    sync.Mutex
+	files map[string]*file
+}
+
+// file is a loaded asset with its encodings.
+type file struct {
+	typ       string
+	etag      string
+	encodings map[string][]byte // "" is the identity encoding
+}
+
+// New returns a Server for fsys.
+func New(fsys fs.FS, reserved ...string) *Server {
+	return &Server{FS: fsys, Reserved: reserved, files: map[string]*file{}}
+}
+
+// Handle serves GET and HEAD requests, meant for gin's NoRoute: an existing
+// file as-is, reserved paths and missing files with an extension as 404,
+// and anything else as index.html so the client router can take it.
+func (s *Server) Handle(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	urlPath := c.Request.URL.Path
+	for _, prefix := range s.Reserved {
+		if strings.HasPrefix(urlPath, prefix) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no route for " + urlPath})
+			return
+		}
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" || strings.HasPrefix(path.Base(name), ".") {
+		name = "index.html"
+	}
+	f, err := s.load(name)
+	if err != nil {
+		if path.Ext(name) != "" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		name = "index.html"
+		if f, err = s.load(name); err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+	}
+
+	h := c.Writer.Header()
+	switch {
+	case name == "index.html":
+		h.Set("Cache-Control", cacheIndex)
+	case strings.HasPrefix(name, "assets/"):
+		h.Set("Cache-Control", cacheImmutable)
+	default:
+		h.Set("Cache-Control", cacheDefault)
+	}
+	h.Set("ETag", f.etag)
+	h.Add("Vary", "Accept-Encoding")
+	if c.GetHeader("If-None-Match") == f.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	enc, body := f.negotiate(c.GetHeader("Accept-Encoding"))
+	if enc != "" {
+		h.Set("Content-Encoding", enc)
+	}
+	c.Data(http.StatusOK, f.typ, body)
+}
+
+// load reads name and its precompressed .br and .gz siblings, if any, once.
+// Compressible files without a .gz get one made here.
+func (s *Server) load(name string) (*file, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.files[name]; ok {
+		return f, nil
+	}
+
+	data, err := fs.ReadFile(s.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	f := &file{
+		typ:       mime.TypeByExtension(path.Ext(name)),
+		etag:      `"` + hex.EncodeToString(sum[:8]) + `"`,
+		encodings: map[string][]byte{"": data},
+	}
+	if f.typ == "" {
+		f.typ = http.DetectContentType(data)
+	}
+	for enc, ext := range map[string]string{"br": ".br", "gzip": ".gz"} {
+		if b, err := fs.ReadFile(s.FS, name+ext); err == nil {
+			f.encodings[enc] = b
+		}
+	}
+	if _, ok := f.encodings["gzip"]; !ok && len(data) >= minGzipSize && compressible(f.typ) {
+		if b, err := gzipBytes(data); err == nil && len(b) < len(data) {
+			f.encodings["gzip"] = b
+		}
+	}
+
+	s.files[name] = f
+	return f, nil
+}
+
+// negotiate picks brotli, then gzip, then identity, as accepted.
+func (f *file) negotiate(acceptEncoding string) (string, []byte) {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		enc, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(params, " ", "") == "q=0" {
+			continue
+		}
+		accepted[strings.ToLower(enc)] = true
+	}
+	for _, enc := range []string{"br", "gzip"} {
+		if b, ok := f.encodings[enc]; ok && accepted[enc] {
+			return enc, b
+		}
+	}
+	return "", f.encodings[""]
+}
+
+func compressible(typ string) bool {
+	base, _, _ := strings.Cut(typ, ";")
+	switch {
+	case strings.HasPrefix(base, "text/"):
+		return true
+	case base == "application/javascript", base == "application/json",
+		base == "image/svg+xml", base == "application/manifest+json":
+		return true
+	}
+	return false
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
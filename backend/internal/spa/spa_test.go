@@ -0,0 +1,93 @@
+package spa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setup() *gin.Engine {
+	big := strings.Repeat("console.log('dyel');\n", 200)
+	fsys := fstest.MapFS{
+		"index.html":           {Data: []byte("<!doctype html><div id=app></div>")},
+		"assets/index-abc.js":  {Data: []byte(big)},
+		"assets/index-abc.css": {Data: []byte("body{}")},
+		"assets/logo.svg":      {Data: []byte("<svg/>")},
+		"assets/logo.svg.br":   {Data: []byte("brotli!")},
+		"vite.svg":             {Data: []byte("<svg/>")},
+	}
+	r := gin.New()
+	r.GET("/api/v1/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	r.NoRoute(New(fsys, "/api/").Handle)
+	return r
+}
+
+func get(r *gin.Engine, url string, hdr ...string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", url, nil)
+	for i := 0; i+1 < len(hdr); i += 2 {
+		req.Header.Set(hdr[i], hdr[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestFallbackToIndex(t *testing.T) {
+	r := setup()
+
+	for _, url := range []string{"/", "/programs/123", "/programs/123/edit"} {
+		w := get(r, url)
+		assert.Equal(t, http.StatusOK, w.Code, url)
+		assert.Contains(t, w.Body.String(), `<div id=app>`, url)
+		assert.Equal(t, cacheIndex, w.Header().Get("Cache-Control"))
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(r, "/assets/missing.js").Code)
+	w := get(r, "/api/v1/nope")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "error")
+	assert.Equal(t, "pong", get(r, "/api/v1/ping").Body.String())
+}
+
+func TestCacheHeaders(t *testing.T) {
+	r := setup()
+
+	w := get(r, "/assets/index-abc.css")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, cacheImmutable, w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/css")
+
+	assert.Equal(t, cacheDefault, get(r, "/vite.svg").Header().Get("Cache-Control"))
+
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusNotModified, get(r, "/assets/index-abc.css", "If-None-Match", etag).Code)
+}
+
+func TestCompression(t *testing.T) {
+	r := setup()
+
+	w := get(r, "/assets/index-abc.js", "Accept-Encoding", "gzip, br")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	zr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(zr)
+	assert.Contains(t, string(body), "dyel")
+
+	w = get(r, "/assets/logo.svg", "Accept-Encoding", "gzip, br")
+	assert.Equal(t, "br", w.Header().Get("Content-Encoding"), "precompressed sibling")
+	assert.Equal(t, "brotli!", w.Body.String())
+
+	w = get(r, "/assets/logo.svg", "Accept-Encoding", "br;q=0")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "<svg/>", w.Body.String())
+}
@@ -0,0 +1,70 @@
+// Package stats summarises the training volume of a program.
+package stats
+
+import "github.com/iraunchy/dyel/backend/db"
+
+// ProgramStats is the summary of a whole program.
+type ProgramStats struct {
+	ProgramID string         `json:"program_id"`
+	Days      int            `json:"days"`
+	Exercises int            `json:"exercises"`
+	Sets      int            `json:"sets"`
+	Groups    map[string]int `json:"groups"`
+	PerDay    []DayStats     `json:"per_day"`
+}
+
+// DayStats is the summary of a single day. Groups counts the day's exercises
+// by the type of group they are in, so its counts add up to Exercises;
+// exercises outside any group count as "straight".
+type DayStats struct {
+	DayID     string         `json:"day_id"`
+	Name      string         `json:"name"`
+	Exercises int            `json:"exercises"`
+	Sets      int            `json:"sets"`
+	Groups    map[string]int `json:"groups"`
+}
+
+// Compute summarises p. An exercise in a group with Rounds set is counted
+// as one set per round, so a 3-round circuit adds three sets per exercise.
+func Compute(p *db.Program) ProgramStats {
+	out := ProgramStats{
+		ProgramID: p.ID,
+		Days:      len(p.Days),
+		Groups:    map[string]int{},
+		PerDay:    make([]DayStats, 0, len(p.Days)),
+	}
+	for _, d := range p.Days {
+		day := computeDay(d)
+		out.Exercises += day.Exercises
+		out.Sets += day.Sets
+		for typ, n := range day.Groups {
+			out.Groups[typ] += n
+		}
+		out.PerDay = append(out.PerDay, day)
+	}
+	return out
+}
+
+func computeDay(d db.Day) DayStats {
+	day := DayStats{
+		DayID:     d.ID,
+		Name:      d.Name,
+		Exercises: len(d.Exercises),
+		Groups:    map[string]int{},
+	}
+	for _, ex := range d.Exercises {
+		g := d.GroupFor(ex)
+		switch {
+		case g == nil:
+			day.Groups[db.GroupStraight]++
+			day.Sets += ex.Sets
+		case g.Rounds > 0:
+			day.Groups[g.Type]++
+			day.Sets += g.Rounds
+		default:
+			day.Groups[g.Type]++
+			day.Sets += ex.Sets
+		}
+	}
+	return day
+}
@@ -0,0 +1,74 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+func TestCompute(t *testing.T) {
+	p := &db.Program{ID: "p1", Days: []db.Day{
+		{
+			ID:   "d1",
+			Name: "Push",
+			Groups: []db.ExerciseGroup{
+				{Label: "A", Type: db.GroupSuperset},
+				{Label: "B", Type: db.GroupCircuit, Rounds: 3},
+			},
+			Exercises: []db.Exercise{
+				{Name: "Overhead Press", Sets: 5},
+				{Name: "Dips", Sets: 3},
+				{Name: "Bench", Sets: 4, Group: "A"},
+				{Name: "Row", Sets: 4, Group: "A"},
+				{Name: "Burpees", Group: "B"},
+				{Name: "Push-ups", Sets: 2, Group: "B"},
+			},
+		},
+		{
+			ID:        "d2",
+			Name:      "Legs",
+			Exercises: []db.Exercise{{Name: "Squat", Sets: 5}},
+		},
+	}}
+
+	s := Compute(p)
+	assert.Equal(t, "p1", s.ProgramID)
+	assert.Equal(t, 2, s.Days)
+	assert.Equal(t, 7, s.Exercises)
+	assert.Equal(t, 5+3+4+4+3+3+5, s.Sets)
+	assert.Equal(t, map[string]int{db.GroupStraight: 3, db.GroupSuperset: 2, db.GroupCircuit: 2}, s.Groups)
+
+	if assert.Len(t, s.PerDay, 2) {
+		push := s.PerDay[0]
+		assert.Equal(t, "d1", push.DayID)
+		assert.Equal(t, 6, push.Exercises)
+		assert.Equal(t, 5+3+4+4+3+3, push.Sets)
+		assert.Equal(t, map[string]int{db.GroupStraight: 2, db.GroupSuperset: 2, db.GroupCircuit: 2}, push.Groups)
+
+		assert.Equal(t, DayStats{DayID: "d2", Name: "Legs", Exercises: 1, Sets: 5,
+			Groups: map[string]int{db.GroupStraight: 1}}, s.PerDay[1])
+	}
+}
+
+func TestComputeGroupCountsAddUp(t *testing.T) {
+	// an empty group has no exercises to count
+	day := db.Day{
+		Groups:    []db.ExerciseGroup{{Label: "A", Type: db.GroupEMOM}, {Label: "B", Type: db.GroupAMRAP}},
+		Exercises: []db.Exercise{{Name: "Clean", Sets: 10, Group: "A"}, {Name: "Snatch", Sets: 10, Group: "A"}},
+	}
+	s := Compute(&db.Program{Days: []db.Day{day}})
+	assert.Equal(t, map[string]int{db.GroupEMOM: 2}, s.Groups)
+
+	total := 0
+	for _, n := range s.Groups {
+		total += n
+	}
+	assert.Equal(t, s.Exercises, total)
+}
+
+func TestComputeEmpty(t *testing.T) {
+	s := Compute(&db.Program{ID: "p1"})
+	assert.Equal(t, ProgramStats{ProgramID: "p1", Groups: map[string]int{}, PerDay: []DayStats{}}, s)
+}
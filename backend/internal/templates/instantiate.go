@@ -0,0 +1,155 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/warmup"
+)
+
+// Params personalise a program made from a template.
+type Params struct {
+	// Name defaults to the template's name.
+	Name     string
+	SharedBy string
+	// Days are the weekdays to train on, such as "mon" or "Thursday", one
+	// per training day of the week; the template's DefaultDays when empty.
+	Days []string
+	// Maxes are the lifter's training maxes. Every lift in Lifts needs one.
+	Maxes []db.TrainingMax
+}
+
+// MissingMaxesError lists the lifts a template needs training maxes for
+// that weren't given.
+type MissingMaxesError struct {
+	Lifts []string
+}
+
+func (e *MissingMaxesError) Error() string {
+	return "training maxes needed for " + strings.Join(e.Lifts, ", ")
+}
+
+// Instantiate builds a program from t. Days are named after their weekday,
+// and their week when the template runs over several. Percentages of a
+// training max become absolute loads in the max's unit, rounded to what
+// can be loaded on a barbell.
+func (t *Template) Instantiate(p Params) (*db.Program, error) {
+	days := p.Days
+	if len(days) == 0 {
+		days = t.DefaultDays
+	}
+	weekdays, err := parseDays(days, len(t.Weeks[0].Days))
+	if err != nil {
+		return nil, err
+	}
+
+	maxes := make(map[string]db.TrainingMax, len(p.Maxes))
+	for _, m := range p.Maxes {
+		maxes[db.ExerciseKey(m.Exercise)] = m
+	}
+	var missing []string
+	for _, lift := range t.Lifts() {
+		if _, ok := maxes[lift]; !ok {
+			missing = append(missing, lift)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingMaxesError{Lifts: missing}
+	}
+
+	prog := &db.Program{Name: p.Name, SharedBy: p.SharedBy}
+	if prog.Name == "" {
+		prog.Name = t.Name
+	}
+	for wi, w := range t.Weeks {
+		for di, d := range w.Days {
+			name := weekdays[di] + " – " + d.Name
+			if len(t.Weeks) > 1 {
+				name = fmt.Sprintf("Week %d – %s", wi+1, name)
+			}
+			day := db.Day{Name: name}
+			for _, ex := range d.Exercises {
+				day.Exercises = append(day.Exercises, ex.build(maxes))
+			}
+			prog.Days = append(prog.Days, day)
+		}
+	}
+	return prog, nil
+}
+
+func (ex Exercise) build(maxes map[string]db.TrainingMax) db.Exercise {
+	tm := maxes[db.ExerciseKey(ex.tm())]
+	out := db.Exercise{
+		Name:      ex.Name,
+		Sets:      ex.Sets,
+		Reps:      ex.Reps,
+		Rest:      ex.Rest,
+		Intensity: percentOf(ex.PercentTM, tm),
+	}
+	if ex.RPE > 0 {
+		rpe := ex.RPE
+		out.RPE = &rpe
+	}
+	for _, s := range ex.SetPrescriptions {
+		out.SetPrescriptions = append(out.SetPrescriptions, db.SetPrescription{
+			Type:      s.Type,
+			Reps:      s.Reps,
+			Intensity: percentOf(s.PercentTM, tm),
+		})
+	}
+	return out
+}
+
+// percentOf turns pct of tm into an absolute, loadable weight.
+func percentOf(pct float64, tm db.TrainingMax) db.Intensity {
+	if pct == 0 {
+		return db.Intensity{}
+	}
+	v := tm.Value * pct / 100
+	if r, ok := warmup.DefaultRounding[tm.Unit]; ok {
+		v = r.Round(v)
+	}
+	return db.Intensity{Load: &v, LoadUnit: tm.Unit}
+}
+
+var weekdayNames = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// parseDays checks that days holds n different weekdays and returns their
+// short names in week order.
+func parseDays(days []string, n int) ([]string, error) {
+	if len(days) != n {
+		return nil, fmt.Errorf("want %d training days, got %d", n, len(days))
+	}
+	var idx []int
+	seen := map[int]bool{}
+	for _, d := range days {
+		i := weekday(d)
+		switch {
+		case i < 0:
+			return nil, fmt.Errorf("%q is not a day of the week", d)
+		case seen[i]:
+			return nil, fmt.Errorf("%s is given twice", d)
+		}
+		seen[i] = true
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	out := make([]string, len(idx))
+	for k, i := range idx {
+		out[k] = strings.ToUpper(weekdayNames[i][:1]) + weekdayNames[i][1:3]
+	}
+	return out, nil
+}
+
+// weekday accepts a full or three-letter day name in any case.
+func weekday(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range weekdayNames {
+		if s == name || s == name[:3] {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,244 @@
+// Package templates is the library of well-known programs shipped with the
+// binary, and turns them into programs for a particular lifter.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+//go:embed library/*.yaml
+var files embed.FS
+
+// Template is one program in the library. Bump Version whenever its
+// content changes; GET /templates reports it so clients can notice a
+// template has changed. Programs made from a template don't record which
+// one or at what version.
+type Template struct {
+	Slug        string   `yaml:"slug"`
+	Name        string   `yaml:"name"`
+	Version     int      `yaml:"version"`
+	Author      string   `yaml:"author"`
+	Description string   `yaml:"description"`
+	DefaultDays []string `yaml:"default_days"`
+	Weeks       []Week   `yaml:"weeks"`
+}
+
+// Week is one week of a template's cycle; every week has the same number of
+// training days.
+type Week struct {
+	Days []Day `yaml:"days"`
+}
+
+// Day is one session of a Week.
+type Day struct {
+	Name      string     `yaml:"name"`
+	Exercises []Exercise `yaml:"exercises"`
+}
+
+// Exercise is a templated db.Exercise. PercentTM loads are worked out
+// against the training max of TM, or of Name when TM is empty.
+type Exercise struct {
+	Name             string  `yaml:"name"`
+	TM               string  `yaml:"tm"`
+	Sets             int     `yaml:"sets"`
+	Reps             string  `yaml:"reps"`
+	Rest             string  `yaml:"rest"`
+	PercentTM        float64 `yaml:"percent_tm"`
+	RPE              float64 `yaml:"rpe"`
+	SetPrescriptions []Set   `yaml:"set_prescriptions"`
+}
+
+// Set is a templated db.SetPrescription.
+type Set struct {
+	Type      string  `yaml:"type"`
+	Reps      string  `yaml:"reps"`
+	PercentTM float64 `yaml:"percent_tm"`
+}
+
+// Summary describes a template for GET /templates.
+type Summary struct {
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	Version     int      `json:"version"`
+	Author      string   `json:"author"`
+	Description string   `json:"description"`
+	DaysPerWeek int      `json:"days_per_week"`
+	Weeks       int      `json:"weeks"`
+	DefaultDays []string `json:"default_days"`
+	// TrainingMaxes are the lifts whose training maxes the template's
+	// percentages are based on, as training max exercise names.
+	TrainingMaxes []string `json:"training_maxes"`
+}
+
+// Library holds the embedded templates.
+type Library struct {
+	list   []*Template
+	bySlug map[string]*Template
+}
+
+// Load parses and checks every embedded template.
+func Load() (*Library, error) {
+	return load(files)
+}
+
+func load(fsys fs.FS) (*Library, error) {
+	paths, err := fs.Glob(fsys, "library/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	lib := &Library{bySlug: map[string]*Template{}}
+	for _, path := range paths {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		var t Template
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&t); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if _, dup := lib.bySlug[t.Slug]; dup {
+			return nil, fmt.Errorf("%s: slug %q is taken", path, t.Slug)
+		}
+		lib.list = append(lib.list, &t)
+		lib.bySlug[t.Slug] = &t
+	}
+	sort.Slice(lib.list, func(i, j int) bool { return lib.list[i].Name < lib.list[j].Name })
+	return lib, nil
+}
+
+// List summarises every template, by name.
+func (l *Library) List() []Summary {
+	out := make([]Summary, len(l.list))
+	for i, t := range l.list {
+		out[i] = t.Summary()
+	}
+	return out
+}
+
+// Get returns the template with the given slug.
+func (l *Library) Get(slug string) (*Template, bool) {
+	t, ok := l.bySlug[slug]
+	return t, ok
+}
+
+// Summary describes t.
+func (t *Template) Summary() Summary {
+	return Summary{
+		Slug:          t.Slug,
+		Name:          t.Name,
+		Version:       t.Version,
+		Author:        t.Author,
+		Description:   t.Description,
+		DaysPerWeek:   len(t.Weeks[0].Days),
+		Weeks:         len(t.Weeks),
+		DefaultDays:   t.DefaultDays,
+		TrainingMaxes: t.Lifts(),
+	}
+}
+
+// Lifts returns the training max exercise names t's percentages need,
+// sorted.
+func (t *Template) Lifts() []string {
+	seen := map[string]bool{}
+	lifts := []string{}
+	add := func(ex Exercise, pct float64) {
+		if key := db.ExerciseKey(ex.tm()); pct > 0 && !seen[key] {
+			seen[key] = true
+			lifts = append(lifts, key)
+		}
+	}
+	for _, w := range t.Weeks {
+		for _, d := range w.Days {
+			for _, ex := range d.Exercises {
+				add(ex, ex.PercentTM)
+				for _, s := range ex.SetPrescriptions {
+					add(ex, s.PercentTM)
+				}
+			}
+		}
+	}
+	sort.Strings(lifts)
+	return lifts
+}
+
+func (ex Exercise) tm() string {
+	if ex.TM != "" {
+		return ex.TM
+	}
+	return ex.Name
+}
+
+var setTypes = []string{db.SetWarmup, db.SetWorking, db.SetDrop, db.SetAMRAP}
+
+// validate catches mistakes in a template file when the library loads.
+func (t *Template) validate() error {
+	switch {
+	case t.Slug == "" || t.Name == "":
+		return fmt.Errorf("slug and name are required")
+	case t.Version < 1:
+		return fmt.Errorf("version must be positive")
+	case len(t.Weeks) == 0 || len(t.Weeks[0].Days) == 0:
+		return fmt.Errorf("no training days")
+	}
+	perWeek := len(t.Weeks[0].Days)
+	if _, err := parseDays(t.DefaultDays, perWeek); err != nil {
+		return fmt.Errorf("default_days: %w", err)
+	}
+	for wi, w := range t.Weeks {
+		if len(w.Days) != perWeek {
+			return fmt.Errorf("week %d has %d days, week 1 has %d", wi+1, len(w.Days), perWeek)
+		}
+		for _, d := range w.Days {
+			if d.Name == "" || len(d.Exercises) == 0 {
+				return fmt.Errorf("week %d: every day needs a name and exercises", wi+1)
+			}
+			for _, ex := range d.Exercises {
+				if err := ex.validate(); err != nil {
+					return fmt.Errorf("week %d, %s, %s: %w", wi+1, d.Name, ex.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (ex Exercise) validate() error {
+	switch {
+	case ex.Name == "":
+		return fmt.Errorf("name is required")
+	case len(ex.SetPrescriptions) == 0 && (ex.Sets < 1 || ex.Reps == ""):
+		return fmt.Errorf("needs sets and reps, or set_prescriptions")
+	case ex.PercentTM < 0 || ex.PercentTM > 150:
+		return fmt.Errorf("percent_tm %g is out of range", ex.PercentTM)
+	case ex.PercentTM > 0 && ex.RPE > 0:
+		return fmt.Errorf("percent_tm and rpe are mutually exclusive")
+	}
+	for _, s := range ex.SetPrescriptions {
+		ok := false
+		for _, typ := range setTypes {
+			ok = ok || s.Type == typ
+		}
+		switch {
+		case !ok:
+			return fmt.Errorf("set type %q is not one of %v", s.Type, setTypes)
+		case s.Reps == "":
+			return fmt.Errorf("every set needs reps")
+		case s.PercentTM < 0 || s.PercentTM > 150:
+			return fmt.Errorf("percent_tm %g is out of range", s.PercentTM)
+		}
+	}
+	return nil
+}
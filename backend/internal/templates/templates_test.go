@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+func TestLibrary(t *testing.T) {
+	lib, err := Load()
+	require.NoError(t, err)
+
+	var slugs []string
+	for _, s := range lib.List() {
+		slugs = append(slugs, s.Slug)
+	}
+	assert.ElementsMatch(t, []string{"starting-strength", "531-bbb", "gzclp", "phul", "nsuns-5day"}, slugs)
+
+	bbb, ok := lib.Get("531-bbb")
+	require.True(t, ok)
+	s := bbb.Summary()
+	assert.Equal(t, 4, s.DaysPerWeek)
+	assert.Equal(t, 4, s.Weeks)
+	assert.Equal(t, []string{"back squat", "bench press", "deadlift", "overhead press"}, s.TrainingMaxes)
+
+	ss, _ := lib.Get("starting-strength")
+	assert.Empty(t, ss.Lifts())
+	nsuns, _ := lib.Get("nsuns-5day")
+	assert.Equal(t, s.TrainingMaxes, nsuns.Lifts(), "T2 lifts use the main lifts' maxes")
+}
+
+func TestInstantiate(t *testing.T) {
+	lib, err := Load()
+	require.NoError(t, err)
+	bbb, _ := lib.Get("531-bbb")
+	maxes := []db.TrainingMax{
+		{Exercise: "Back Squat", Value: 140, Unit: db.UnitKg},
+		{Exercise: "bench press", Value: 100, Unit: db.UnitKg},
+		{Exercise: "Deadlift", Value: 180, Unit: db.UnitKg},
+		{Exercise: "Overhead Press", Value: 135, Unit: db.UnitLb},
+	}
+
+	p, err := bbb.Instantiate(Params{SharedBy: "a@example.com", Days: []string{"Saturday", "mon", "wed", "THU"}, Maxes: maxes})
+	require.NoError(t, err)
+	assert.Equal(t, "5/3/1 Boring But Big", p.Name)
+	require.Len(t, p.Days, 16)
+	assert.Equal(t, "Week 1 – Mon – Press", p.Days[0].Name)
+	assert.Equal(t, "Week 1 – Sat – Squat", p.Days[3].Name)
+	assert.Equal(t, "Week 4 – Thu – Bench", p.Days[14].Name)
+
+	squat := p.Days[3].Exercises[0]
+	require.Len(t, squat.SetPrescriptions, 3)
+	assert.Equal(t, 90.0, *squat.SetPrescriptions[0].Load, "65% of 140 kg rounds to 90")
+	assert.Equal(t, db.UnitKg, squat.SetPrescriptions[0].LoadUnit)
+	assert.Equal(t, db.SetAMRAP, squat.SetPrescriptions[2].Type)
+	press := p.Days[0].Exercises[1]
+	assert.Equal(t, 70.0, *press.Load, "50% of 135 lb rounds to 70")
+	assert.Equal(t, db.UnitLb, press.LoadUnit)
+	for _, d := range p.Days {
+		for _, ex := range d.Exercises {
+			assert.NoError(t, ex.Validate())
+		}
+	}
+
+	_, err = bbb.Instantiate(Params{Maxes: maxes[:2]})
+	var missing *MissingMaxesError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, []string{"deadlift", "overhead press"}, missing.Lifts)
+
+	_, err = bbb.Instantiate(Params{Days: []string{"mon", "tue", "wed"}, Maxes: maxes})
+	assert.ErrorContains(t, err, "want 4 training days, got 3")
+	_, err = bbb.Instantiate(Params{Days: []string{"mon", "monday", "wed", "fri"}, Maxes: maxes})
+	assert.ErrorContains(t, err, "given twice")
+	_, err = bbb.Instantiate(Params{Days: []string{"mon", "tue", "wed", "someday"}, Maxes: maxes})
+	assert.ErrorContains(t, err, `"someday" is not a day of the week`)
+
+	ss, _ := lib.Get("starting-strength")
+	p, err = ss.Instantiate(Params{Name: "My SS", SharedBy: "a@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "My SS", p.Name)
+	assert.Equal(t, []string{
+		"Week 1 – Mon – Workout A", "Week 1 – Wed – Workout B", "Week 1 – Fri – Workout A",
+		"Week 2 – Mon – Workout B", "Week 2 – Wed – Workout A", "Week 2 – Fri – Workout B",
+	}, dayNames(p))
+}
+
+func TestLoadRejectsBadTemplates(t *testing.T) {
+	for name, body := range map[string]string{
+		"unknown key": "slug: x\nname: X\nversion: 1\nlevel: hard\n",
+		"no version":  "slug: x\nname: X\ndefault_days: [mon]\nweeks: [{days: [{name: A, exercises: [{name: Squat, sets: 3, reps: '5'}]}]}]\n",
+		"day count":   "slug: x\nname: X\nversion: 1\ndefault_days: [mon, tue]\nweeks: [{days: [{name: A, exercises: [{name: Squat, sets: 3, reps: '5'}]}]}]\n",
+		"set type":    "slug: x\nname: X\nversion: 1\ndefault_days: [mon]\nweeks: [{days: [{name: A, exercises: [{name: Squat, set_prescriptions: [{type: heavy, reps: '5'}]}]}]}]\n",
+	} {
+		_, err := load(fstest.MapFS{"library/x.yaml": {Data: []byte(body)}})
+		assert.Error(t, err, name)
+	}
+}
+
+func dayNames(p *db.Program) []string {
+	var names []string
+	for _, d := range p.Days {
+		names = append(names, d.Name)
+	}
+	return names
+}
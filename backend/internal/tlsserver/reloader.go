@@ -0,0 +1,106 @@
+// Package tlsserver lets dyel terminate TLS itself: a certificate source
+// that picks up renewed files without a restart, and a plain-HTTP listener
+// that redirects to HTTPS.
+package tlsserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves the key pair in CertFile/KeyFile and reloads it when
+// either file's modification time or size changes.
+type Reloader struct {
+	CertFile string
+	KeyFile  string
+
+	mu    sync.RWMutex
+	cert  *tls.Certificate
+	stamp string
+}
+
+// NewReloader loads the key pair once, failing if it can't be read.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{CertFile: certFile, KeyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate is meant for tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// TLSConfig returns a server config backed by the reloader.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Reload re-reads the key pair if the files changed since the last load and
+// reports whether it did. On error the previous certificate stays in use.
+func (r *Reloader) Reload() (bool, error) {
+	stamp, err := r.stampFiles()
+	if err != nil {
+		return false, err
+	}
+	r.mu.RLock()
+	same := stamp == r.stamp
+	r.mu.RUnlock()
+	if same {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("load key pair: %w", err)
+	}
+	r.mu.Lock()
+	r.cert, r.stamp = &cert, stamp
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Watch checks the files every interval until ctx is cancelled.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		switch reloaded, err := r.Reload(); {
+		case err != nil:
+			slog.Error("tls: keeping current certificate", "err", err)
+		case reloaded:
+			slog.Info("tls: reloaded certificate", "file", r.CertFile)
+		}
+	}
+}
+
+// stampFiles summarises both files' modification times and sizes.
+func (r *Reloader) stampFiles() (string, error) {
+	var stamp string
+	for _, name := range []string{r.CertFile, r.KeyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%d:%d;", fi.ModTime().UnixNano(), fi.Size())
+	}
+	return stamp, nil
+}
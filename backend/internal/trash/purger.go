@@ -0,0 +1,48 @@
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/iraunchy/dyel/backend/internal/repos"
+)
+
+// Purger permanently removes programs that have sat in the trash for longer
+// than Retention, checking every Interval.
+type Purger struct {
+	Repo      repos.TrashRepo
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+// NewPurger wires in a TrashRepo.
+func NewPurger(r repos.TrashRepo, retention, interval time.Duration) *Purger {
+	return &Purger{Repo: r, Retention: retention, Interval: interval}
+}
+
+// Run purges once immediately and then on every tick until ctx is cancelled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.PurgeOnce(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			slog.Error("trash: purge failed", "err", err)
+		case n > 0:
+			slog.Info("trash: purged programs", "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce removes everything trashed before now minus Retention.
+func (p *Purger) PurgeOnce(ctx context.Context) (int64, error) {
+	return p.Repo.Purge(ctx, time.Now().Add(-p.Retention))
+}
@@ -0,0 +1,250 @@
+// Package warmup builds warm-up ramps leading up to a working load.
+package warmup
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/plates"
+)
+
+// DefaultTemplate is used when a request names no template.
+const DefaultTemplate = "standard"
+
+// Step is one warm-up set: Reps at Percent of the working load, or with the
+// empty bar when Percent is zero.
+type Step struct {
+	Percent float64 `json:"percent"`
+	Reps    int     `json:"reps"`
+}
+
+// Template is a named warm-up ramp.
+type Template struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+}
+
+// Defaults are the built-in templates.
+var Defaults = map[string]Template{
+	"standard": mustParse("standard", "bar:10,40:5,60:3,80:1"),
+	"short":    mustParse("short", "bar:5,50:3,75:1"),
+	"long":     mustParse("long", "bar:10,bar:5,30:5,50:3,70:2,85:1"),
+}
+
+// Parse reads a ramp written as comma-separated percent:reps steps, where
+// "bar" stands for the empty bar: "bar:10,40:5,60:3,80:1".
+func Parse(name, spec string) (Template, error) {
+	t := Template{Name: name}
+	for _, part := range strings.Split(spec, ",") {
+		pct, reps, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return Template{}, fmt.Errorf("warm-up step %q: want percent:reps", part)
+		}
+		var s Step
+		if pct != "bar" {
+			p, err := strconv.ParseFloat(pct, 64)
+			if err != nil || p <= 0 || p >= 100 {
+				return Template{}, fmt.Errorf("warm-up step %q: percent must be between 0 and 100", part)
+			}
+			s.Percent = p
+		}
+		n, err := strconv.Atoi(reps)
+		if err != nil || n < 1 {
+			return Template{}, fmt.Errorf("warm-up step %q: reps must be a positive integer", part)
+		}
+		s.Reps = n
+		t.Steps = append(t.Steps, s)
+	}
+	return t, nil
+}
+
+// ParseTemplates reads templates written as name=spec pairs separated by
+// ";", e.g. "oly=bar:5,50:3,70:2,85:1;quick=bar:5,60:3". The result starts
+// from Defaults, so a template of the same name replaces the built-in one.
+func ParseTemplates(s string) (map[string]Template, error) {
+	out := make(map[string]Template, len(Defaults))
+	for name, t := range Defaults {
+		out[name] = t
+	}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("warm-up template %q: want name=spec", entry)
+		}
+		t, err := Parse(name, spec)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = t
+	}
+	return out, nil
+}
+
+func mustParse(name, spec string) Template {
+	t, err := Parse(name, spec)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Names lists templates alphabetically.
+func Names(templates map[string]Template) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rounding describes the loads that can actually be put on the bar: the
+// bar itself plus pairs of plates in steps of Increment or, when Plates is
+// set, whatever those plates can make.
+type Rounding struct {
+	Bar       float64    `json:"bar"`
+	Increment float64    `json:"increment"`
+	Plates    []db.Plate `json:"plates,omitempty"`
+}
+
+// InventoryRounding rounds to the bar and plates in inv.
+func InventoryRounding(inv db.PlateInventory) Rounding {
+	return Rounding{Bar: inv.Bar, Plates: inv.Plates}
+}
+
+// DefaultRounding is a standard barbell with the smallest common plates:
+// a 20 kg bar with 1.25 kg plates, or a 45 lb bar with 2.5 lb plates.
+var DefaultRounding = map[string]Rounding{
+	db.UnitKg: {Bar: 20, Increment: 2.5},
+	db.UnitLb: {Bar: 45, Increment: 5},
+}
+
+// Round returns the nearest loadable weight to v, never below the bar.
+func (r Rounding) Round(v float64) float64 {
+	if v <= r.Bar {
+		return r.Bar
+	}
+	if len(r.Plates) > 0 {
+		return plates.Calculate(v, db.PlateInventory{Bar: r.Bar, Plates: r.Plates}).Achieved
+	}
+	if r.Increment <= 0 {
+		return r.Bar
+	}
+	return r.Bar + math.Round((v-r.Bar)/r.Increment)*r.Increment
+}
+
+// Set is one calculated warm-up set.
+type Set struct {
+	Load    float64 `json:"load"`
+	Reps    int     `json:"reps"`
+	Percent float64 `json:"percent"`
+}
+
+// Ramp applies t to working, rounding each load with r. Steps that round
+// to the same load as the previous one, or up to the working load itself,
+// are dropped. Loads no heavier than the bar need no ramp.
+func Ramp(t Template, working float64, r Rounding) []Set {
+	sets := []Set{}
+	if working <= r.Bar {
+		return sets
+	}
+	for _, s := range t.Steps {
+		load := r.Bar
+		if s.Percent > 0 {
+			load = r.Round(working * s.Percent / 100)
+		}
+		if load >= working && s.Percent > 0 {
+			break
+		}
+		if n := len(sets); n > 0 && sets[n-1].Load == load && s.Percent > 0 {
+			continue
+		}
+		pct := math.Round(load / working * 100)
+		sets = append(sets, Set{Load: load, Reps: s.Reps, Percent: pct})
+	}
+	return sets
+}
+
+// Exercise is the warm-up for one exercise of a day.
+type Exercise struct {
+	ExerciseID  string  `json:"exercise_id"`
+	Name        string  `json:"name"`
+	WorkingLoad float64 `json:"working_load"`
+	LoadUnit    string  `json:"load_unit"`
+	Sets        []Set   `json:"sets"`
+}
+
+// DayPlan is the warm-up for every exercise of a day that has a working
+// load.
+type DayPlan struct {
+	DayID     string     `json:"day_id"`
+	Template  string     `json:"template"`
+	Exercises []Exercise `json:"exercises"`
+}
+
+// Plan ramps up to each exercise's working load in d. Loads are given in
+// unit, or in each exercise's own unit when unit is empty; rounding maps a
+// unit to the plates available for it.
+func Plan(d db.Day, t Template, unit string, rounding map[string]Rounding) DayPlan {
+	plan := DayPlan{DayID: d.ID, Template: t.Name, Exercises: []Exercise{}}
+	for _, ex := range d.Exercises {
+		load, from, ok := WorkingLoad(ex)
+		if !ok {
+			continue
+		}
+		to := unit
+		if to == "" {
+			to = from
+		}
+		load = db.ConvertLoad(load, from, to)
+		plan.Exercises = append(plan.Exercises, Exercise{
+			ExerciseID:  ex.ID,
+			Name:        ex.Name,
+			WorkingLoad: load,
+			LoadUnit:    to,
+			Sets:        Ramp(t, load, rounding[to]),
+		})
+	}
+	return plan
+}
+
+// WorkingLoad is the heaviest non-warm-up load prescribed for ex, whether
+// absolute or resolved from a training max, across the exercise and its
+// individual sets.
+func WorkingLoad(ex db.Exercise) (load float64, unit string, ok bool) {
+	consider := func(i db.Intensity) {
+		var v float64
+		var u string
+		switch {
+		case i.Load != nil && i.LoadUnit != "":
+			v, u = *i.Load, i.LoadUnit
+		case i.Resolved != nil:
+			v, u = i.Resolved.Load, i.Resolved.LoadUnit
+		default:
+			return
+		}
+		if !ok {
+			load, unit, ok = v, u, true
+			return
+		}
+		if v = db.ConvertLoad(v, u, unit); v > load {
+			load = v
+		}
+	}
+
+	consider(ex.Intensity)
+	for _, s := range ex.SetPrescriptions {
+		if s.Type != db.SetWarmup {
+			consider(s.Intensity)
+		}
+	}
+	return load, unit, ok
+}
@@ -0,0 +1,55 @@
+package warmup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/iraunchy/dyel/backend/db"
+)
+
+func TestRampRoundsToPlates(t *testing.T) {
+	sets := Ramp(Defaults["standard"], 142.5, DefaultRounding[db.UnitKg])
+	assert.Equal(t, []Set{
+		{Load: 20, Reps: 10, Percent: 14},
+		{Load: 57.5, Reps: 5, Percent: 40},
+		{Load: 85, Reps: 3, Percent: 60},
+		{Load: 115, Reps: 1, Percent: 81},
+	}, sets)
+}
+
+func TestRampRoundsToInventory(t *testing.T) {
+	inv := db.PlateInventory{Unit: db.UnitKg, Bar: 20, Plates: []db.Plate{{Weight: 20, Count: 2}, {Weight: 10, Count: 2}}}
+	sets := Ramp(Defaults["standard"], 90, InventoryRounding(inv))
+	assert.Equal(t, []Set{
+		{Load: 20, Reps: 10, Percent: 22},
+		{Load: 40, Reps: 5, Percent: 44},
+		{Load: 60, Reps: 3, Percent: 67},
+		{Load: 80, Reps: 1, Percent: 89},
+	}, sets)
+}
+
+func TestRampDropsRedundantSteps(t *testing.T) {
+	// 40% of 50 kg is the empty bar again
+	sets := Ramp(Defaults["standard"], 50, DefaultRounding[db.UnitKg])
+	assert.Equal(t, []Set{
+		{Load: 20, Reps: 10, Percent: 40},
+		{Load: 30, Reps: 3, Percent: 60},
+		{Load: 40, Reps: 1, Percent: 80},
+	}, sets)
+
+	assert.Empty(t, Ramp(Defaults["standard"], 20, DefaultRounding[db.UnitKg]))
+}
+
+func TestParseTemplates(t *testing.T) {
+	got, err := ParseTemplates("quick=bar:5,60:3; standard=bar:8,50:4")
+	assert.NoError(t, err)
+	assert.Equal(t, []Step{{Reps: 5}, {Percent: 60, Reps: 3}}, got["quick"].Steps)
+	assert.Len(t, got["standard"].Steps, 2, "overrides the built-in")
+	assert.Contains(t, got, "long")
+
+	for _, bad := range []string{"x", "x=bar", "x=120:3", "x=50:0"} {
+		_, err := ParseTemplates(bad)
+		assert.Error(t, err, bad)
+	}
+}
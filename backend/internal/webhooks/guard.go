@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateDestination rejects endpoints on loopback, private, link-local
+// and other addresses that aren't on the public internet, such as a cloud
+// metadata service.
+var ErrPrivateDestination = errors.New("webhook destination is not a public address")
+
+// Private reports whether ip is somewhere a webhook must not be sent:
+// loopback, private, link-local, shared (CGNAT), unspecified or multicast.
+func Private(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return !ip.IsValid() ||
+		ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckURL rejects endpoint URLs that aren't http or https, or whose host
+// is, or resolves to, a private address. A host that doesn't resolve yet is
+// let through: the worker's dialer checks every address it connects to.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook url must be http or https")
+	}
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if Private(ip) {
+			return ErrPrivateDestination
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range addrs {
+		if Private(ip) {
+			return ErrPrivateDestination
+		}
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client that refuses to connect to private
+// addresses, checked after DNS resolution so a public name pointing at one
+// is caught too. It ignores proxy settings, which would hide the address
+// from the check.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: refusePrivate}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func refusePrivate(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if Private(ap.Addr()) {
+		return fmt.Errorf("dial %s: %w", address, ErrPrivateDestination)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderEvent     = "X-Dyel-Event"
+	HeaderDelivery  = "X-Dyel-Delivery"
+	HeaderTimestamp = "X-Dyel-Timestamp"
+	HeaderSignature = "X-Dyel-Signature"
+)
+
+// Sign returns the signature header value for a payload: "sha256=" followed by
+// the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret.
+// Receivers should recompute it and compare in constant time.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before retry number attempt (1-based): base
+// doubled per attempt and capped at max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return d
+}
+
+// Worker drains the webhook_deliveries outbox, POSTing each row to its
+// endpoint and rescheduling failures with exponential backoff.
+type Worker struct {
+	DB           *gorm.DB
+	Client       *http.Client
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Lease is how long a claimed delivery stays hidden from other workers.
+	Lease time.Duration
+}
+
+// NewWorker wires in a *gorm.DB instance with sensible delivery defaults.
+// Its client refuses private destinations; replace it to deliver to
+// receivers on a local network.
+func NewWorker(dbConn *gorm.DB) *Worker {
+	return &Worker{
+		DB:           dbConn,
+		Client:       NewClient(10 * time.Second),
+		PollInterval: 2 * time.Second,
+		BatchSize:    50,
+		MaxAttempts:  8,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   1 * time.Hour,
+		Lease:        1 * time.Minute,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("webhooks: delivery run failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue attempts every delivery whose next attempt is due and returns
+// how many were attempted.
+func (w *Worker) ProcessDue(ctx context.Context) (int, error) {
+	// The queue is read from the primary: a lagging replica would only
+	// offer deliveries that are already claimed.
+	conn := w.DB.WithContext(ctx).Clauses(dbresolver.Write).Session(&gorm.Session{})
+
+	var due []db.WebhookDelivery
+	if err := conn.
+		Where("delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", time.Now().UTC()).
+		Order("next_attempt_at").
+		Limit(w.BatchSize).
+		Find(&due).
+		Error; err != nil {
+		return 0, fmt.Errorf("load due deliveries: %w", err)
+	}
+
+	attempted := 0
+	for _, d := range due {
+		claimed, err := w.claim(conn, &d)
+		if err != nil {
+			return attempted, err
+		}
+		if !claimed {
+			continue
+		}
+		attempted++
+
+		var ep db.WebhookEndpoint
+		err = conn.First(&ep, "id = ?", d.EndpointID).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !ep.Active):
+			err = w.finish(conn, &d, fmt.Errorf("endpoint %s is gone or inactive", d.EndpointID), true)
+		case err != nil:
+			return attempted, err
+		default:
+			sendErr := w.send(ctx, &ep, &d)
+			err = w.finish(conn, &d, sendErr, false)
+		}
+		if err != nil {
+			return attempted, err
+		}
+	}
+	return attempted, nil
+}
+
+// claim takes ownership of a delivery by bumping its attempt counter. The
+// conditional update makes concurrent workers race safely on the same row.
+func (w *Worker) claim(conn *gorm.DB, d *db.WebhookDelivery) (bool, error) {
+	res := conn.Model(&db.WebhookDelivery{}).
+		Where("id = ? AND attempts = ?", d.ID, d.Attempts).
+		Updates(map[string]any{
+			"attempts":        d.Attempts + 1,
+			"next_attempt_at": time.Now().UTC().Add(w.Lease),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	d.Attempts++
+	return res.RowsAffected == 1, nil
+}
+
+func (w *Worker) send(ctx context.Context, ep *db.WebhookEndpoint, d *db.WebhookDelivery) error {
+	body := []byte(d.Payload)
+	ts := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dyel-webhooks")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(ep.Secret, ts, body))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return nil
+}
+
+// finish records the outcome of an attempt: delivered, rescheduled with
+// backoff, or permanently failed once attempts are exhausted.
+func (w *Worker) finish(conn *gorm.DB, d *db.WebhookDelivery, sendErr error, permanent bool) error {
+	now := time.Now().UTC()
+	updates := map[string]any{}
+
+	switch {
+	case sendErr == nil:
+		updates["delivered_at"] = now
+		updates["last_error"] = ""
+	case permanent || d.Attempts >= w.MaxAttempts:
+		updates["failed_at"] = now
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(Backoff(d.Attempts, w.BaseBackoff, w.MaxBackoff))
+		updates["last_error"] = sendErr.Error()
+	}
+
+	return conn.Model(&db.WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates).Error
+}
@@ -0,0 +1,166 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/repos"
+)
+
+func setupDB(t *testing.T) *gorm.DB {
+	dbConn, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Migrate(dbConn))
+	return dbConn
+}
+
+func TestWorkerDeliversSignedEvent(t *testing.T) {
+	dbConn := setupDB(t)
+	ctx := context.Background()
+
+	var gotEvent, gotSig, gotTS string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEvent = r.Header.Get(HeaderEvent)
+		gotSig = r.Header.Get(HeaderSignature)
+		gotTS = r.Header.Get(HeaderTimestamp)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ep, err := repos.NewGORMWebhookRepo(dbConn).Create(ctx, &db.WebhookEndpoint{
+		URL:    srv.URL,
+		Secret: "s3cret",
+		Events: db.EventProgramCreated,
+	})
+	assert.NoError(t, err)
+
+	programs := repos.NewGORMProgramRepo(dbConn)
+	p, err := programs.Create(ctx, &db.Program{Name: "PPL", SharedBy: "coach@example.com"})
+	assert.NoError(t, err)
+	// not subscribed: must not be queued
+	assert.NoError(t, programs.Delete(ctx, p.ID, 0))
+
+	w := NewWorker(dbConn)
+	w.Client = srv.Client() // the test server is on loopback
+	n, err := w.ProcessDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, db.EventProgramCreated, gotEvent)
+	ts, err := strconv.ParseInt(gotTS, 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, Sign(ep.Secret, ts, gotBody), gotSig)
+	assert.Contains(t, string(gotBody), p.ID)
+
+	var d db.WebhookDelivery
+	assert.NoError(t, dbConn.First(&d, "endpoint_id = ?", ep.ID).Error)
+	assert.NotNil(t, d.DeliveredAt)
+	assert.Equal(t, 1, d.Attempts)
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	dbConn := setupDB(t)
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := repos.NewGORMWebhookRepo(dbConn).Create(ctx, &db.WebhookEndpoint{URL: srv.URL})
+	assert.NoError(t, err)
+	_, err = repos.NewGORMProgramRepo(dbConn).Create(ctx, &db.Program{Name: "5x5", SharedBy: "a@example.com"})
+	assert.NoError(t, err)
+
+	w := NewWorker(dbConn)
+	w.Client = srv.Client()
+	w.MaxAttempts = 2
+
+	n, err := w.ProcessDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	var d db.WebhookDelivery
+	assert.NoError(t, dbConn.First(&d).Error)
+	assert.Nil(t, d.DeliveredAt)
+	assert.Nil(t, d.FailedAt)
+	assert.Contains(t, d.LastError, "502")
+	assert.WithinDuration(t, time.Now().Add(w.BaseBackoff), d.NextAttemptAt, 5*time.Second)
+
+	// not due yet
+	n, err = w.ProcessDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	assert.NoError(t, dbConn.Model(&d).Update("next_attempt_at", time.Now().UTC().Add(-time.Second)).Error)
+	n, err = w.ProcessDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.NoError(t, dbConn.First(&d).Error)
+	assert.NotNil(t, d.FailedAt, "should give up after MaxAttempts")
+}
+
+func TestWorkerRefusesPrivateAddresses(t *testing.T) {
+	dbConn := setupDB(t)
+	ctx := context.Background()
+
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	// stored directly, as if registered before it resolved to loopback
+	_, err := repos.NewGORMWebhookRepo(dbConn).Create(ctx, &db.WebhookEndpoint{URL: srv.URL})
+	assert.NoError(t, err)
+	_, err = repos.NewGORMProgramRepo(dbConn).Create(ctx, &db.Program{Name: "5x5", SharedBy: "a@example.com"})
+	assert.NoError(t, err)
+
+	_, err = NewWorker(dbConn).ProcessDue(ctx)
+	assert.NoError(t, err)
+	assert.False(t, called)
+
+	var d db.WebhookDelivery
+	assert.NoError(t, dbConn.First(&d).Error)
+	assert.Contains(t, d.LastError, ErrPrivateDestination.Error())
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+		"http://100.64.0.1/hook",
+	} {
+		assert.ErrorIs(t, CheckURL(ctx, u), ErrPrivateDestination, u)
+	}
+	assert.Error(t, CheckURL(ctx, "file:///etc/passwd"))
+	assert.NoError(t, CheckURL(ctx, "https://93.184.216.34/hook"))
+	assert.NoError(t, CheckURL(ctx, "https://[2606:2800:220:1:248:1893:25c8:1946]/hook"))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, Backoff(1, time.Second, time.Minute))
+	assert.Equal(t, 4*time.Second, Backoff(3, time.Second, time.Minute))
+	assert.Equal(t, time.Minute, Backoff(20, time.Second, time.Minute))
+}
@@ -1,32 +1,169 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/iraunchy/dyel/backend/db"
+	"github.com/iraunchy/dyel/backend/internal/cache"
 	"github.com/iraunchy/dyel/backend/internal/config"
 	"github.com/iraunchy/dyel/backend/internal/handlers"
+	"github.com/iraunchy/dyel/backend/internal/middleware"
 	"github.com/iraunchy/dyel/backend/internal/repos"
+	"github.com/iraunchy/dyel/backend/internal/spa"
+	"github.com/iraunchy/dyel/backend/internal/templates"
+	"github.com/iraunchy/dyel/backend/internal/tlsserver"
+	"github.com/iraunchy/dyel/backend/internal/trash"
+	"github.com/iraunchy/dyel/backend/internal/webhooks"
+	"github.com/iraunchy/dyel/backend/web"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("config load error: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runServe starts the API server: the default when no command is given.
+func runServe(ctx context.Context, a *app, _ []string) error {
+	cfg := a.cfg
+	if cfg.Logging.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	store := repos.NewGORMProgramRepo(a.db)
+	var repo repos.ProgramStore = store
+	var cacheStats func() cache.Stats
+	if cfg.Cache.Size > 0 {
+		cached := repos.NewCachedProgramRepo(store.Primary(), cache.NewLRU(cfg.Cache.Size), time.Duration(cfg.Cache.TTL))
+		repo, cacheStats = cached, cached.Stats
 	}
 
-	dbConn, err := db.Init(cfg.DatabaseURL)
+	library, err := templates.Load()
 	if err != nil {
-		log.Fatalf("database init error: %v", err)
+		return fmt.Errorf("program templates: %w", err)
 	}
 
-	repo := repos.NewGORMProgramRepo(dbConn)
-	h := handlers.NewHandler(repo)
+	worker := webhooks.NewWorker(a.db)
+	if cfg.Webhooks.AllowPrivateNetworks {
+		worker.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	go worker.Run(ctx)
+	go trash.NewPurger(store,
+		time.Duration(cfg.Programs.TrashRetention),
+		time.Duration(cfg.Programs.TrashPurgeInterval),
+	).Run(ctx)
+
+	h := handlers.NewHandler(repo,
+		handlers.WithTrash(repo),
+		handlers.WithExport(store),
+		handlers.WithTemplates(library),
+		handlers.WithWebhooks(repos.NewGORMWebhookRepo(a.db)),
+		handlers.WithPrivateWebhooks(cfg.Webhooks.AllowPrivateNetworks),
+		handlers.WithSearch(repos.NewGORMSearchRepo(a.db)),
+		handlers.WithTrainingMaxes(repos.NewGORMTrainingMaxRepo(a.db)),
+		handlers.WithPlateInventories(repos.NewGORMPlateInventoryRepo(a.db)),
+		handlers.WithRequireIfMatch(cfg.Programs.RequireIfMatch),
+		handlers.WithBatchLimit(cfg.Programs.BatchMaxItems),
+		handlers.WithWarmupTemplates(cfg.WarmupTemplates),
+		handlers.WithCacheStats(cacheStats),
+		handlers.WithPublicURL(cfg.Server.PublicURL),
+		handlers.WithProgramLimits(cfg.Programs.MaxDays, cfg.Programs.MaxExercises),
+		handlers.WithMiddleware(
+			middleware.RateLimit(
+				limiter(cfg.RateLimit.IP, cfg.RateLimit.IPBurst),
+				limiter(cfg.RateLimit.User, cfg.RateLimit.UserBurst),
+			),
+			middleware.MaxBodySize(cfg.Server.MaxBodyBytes),
+		),
+	)
 
 	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("trusted proxies: %w", err)
+	}
+	router.Use(
+		middleware.SecurityHeaders(cfg.Security.Middleware()),
+		middleware.CORS(cfg.CORS.Middleware()),
+	)
 	h.RegisterRoutes(router)
+	if ui := web.FS(); ui != nil {
+		router.NoRoute(spa.New(ui, "/api/").Handle)
+	}
+
+	if err := serve(ctx, cfg.Server, router); err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
+
+// serve runs the router on cfg.Port until ctx is cancelled, over HTTPS when
+// a certificate is configured, plus the optional HTTP redirect listener.
+// Timeouts stop slow or idle clients holding connections open; the export,
+// which can outlast WriteTimeout, extends its own deadline as it streams.
+func serve(ctx context.Context, cfg config.ServerConfig, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		WriteTimeout:      time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+	servers := []*http.Server{srv}
+	errs := make(chan error, 2)
+
+	if cfg.TLSCertFile == "" {
+		go func() { errs <- srv.ListenAndServe() }()
+	} else {
+		certs, err := tlsserver.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return err
+		}
+		go certs.Watch(ctx, time.Duration(cfg.TLSReloadInterval))
+		srv.TLSConfig = certs.TLSConfig()
+		go func() { errs <- srv.ListenAndServeTLS("", "") }()
+
+		if cfg.HTTPRedirectPort != "" {
+			redirect := &http.Server{
+				Addr:              ":" + cfg.HTTPRedirectPort,
+				Handler:           tlsserver.Redirect(cfg.Port),
+				ReadHeaderTimeout: 10 * time.Second,
+				ReadTimeout:       10 * time.Second,
+				WriteTimeout:      10 * time.Second,
+				IdleTimeout:       time.Minute,
+			}
+			servers = append(servers, redirect)
+			go func() { errs <- redirect.ListenAndServe() }()
+		}
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, s := range servers {
+		if err := s.Shutdown(shutdown); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if err := router.Run(":" + cfg.Port); err != nil {
-		log.Fatalf("server failed: %v", err)
+// limiter returns a token-bucket limiter, or nil when rate is zero.
+func limiter(rate float64, burst int) *middleware.Limiter {
+	if rate == 0 {
+		return nil
 	}
+	return middleware.NewLimiter(rate, burst)
 }
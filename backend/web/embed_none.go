@@ -0,0 +1,8 @@
+//go:build !embedui
+
+package web
+
+import "io/fs"
+
+// FS returns nil: this binary was built without the frontend.
+func FS() fs.FS { return nil }
@@ -0,0 +1,4 @@
+// Package web holds the built frontend when the binary is compiled with
+// -tags embedui. Copy the Vite dist output to web/dist first; see the
+// Makefile's ui target.
+package web
@@ -0,0 +1,385 @@
+// Code generated from backend/api/openapi.json by
+// `go test ./internal/handlers -run OpenAPI -update`. DO NOT EDIT.
+
+export interface BatchItemResult {
+  error?: string
+  id?: string
+  index: number
+  status: string
+}
+
+export interface BatchResult {
+  created: number
+  failed: number
+  items: BatchItemResult[] | null
+  mode: string
+}
+
+export interface Breakdown {
+  achieved: number
+  bar: number
+  exact: boolean
+  per_side: Plate[] | null
+  target: number
+  unit: string
+}
+
+export interface CacheStatsResponse {
+  programs: Stats
+}
+
+export interface CalculatePlatesInput {
+  bar?: number
+  inventory_id?: string
+  plates?: PlateInput[]
+  target: number
+  unit?: "kg" | "lb"
+}
+
+export interface CreatePlateInventoryInput {
+  bar?: number
+  name: string
+  owner: string
+  plates: PlateInput[]
+  unit: "kg" | "lb"
+}
+
+export interface CreateProgramInput {
+  days: DayInput[]
+  name: string
+  shared_by: string
+}
+
+export interface CreateWebhookInput {
+  events?: ("program.created" | "program.updated" | "program.deleted" | "program.forked" | "program.restored" | "*")[]
+  secret?: string
+  url: string
+}
+
+export interface CreatedWebhook {
+  active: boolean
+  created_at: string
+  events: string
+  id: string
+  secret: string
+  updated_at: string
+  url: string
+}
+
+export interface Day {
+  created_at: string
+  exercises: Exercise[] | null
+  groups: ExerciseGroup[] | null
+  id: string
+  name: string
+  program_id: string
+  updated_at: string
+}
+
+export interface DayInput {
+  created_at?: string
+  exercises?: ExerciseInput[]
+  groups?: ExerciseGroupInput[]
+  id?: string
+  name?: string
+  program_id?: string
+  updated_at?: string
+}
+
+export interface DayPlan {
+  day_id: string
+  exercises: WarmupExercise[] | null
+  template: string
+}
+
+export interface DayStats {
+  day_id: string
+  exercises: number
+  groups: Record<string, number> | null
+  name: string
+  sets: number
+}
+
+export interface Error {
+  error: string
+}
+
+export interface Exercise {
+  created_at: string
+  day_id: string
+  group: string
+  id: string
+  load: number | null
+  load_unit: "kg" | "lb" | ""
+  name: string
+  percent_tm: number | null
+  reps: string
+  resolved?: Resolved
+  rest: string
+  rir: number | null
+  rpe: number | null
+  set_prescriptions: SetPrescription[] | null
+  sets: number
+  tempo: string
+  updated_at: string
+}
+
+export interface ExerciseGroup {
+  created_at: string
+  day_id: string
+  id: string
+  label: string
+  rest_between_rounds: string
+  rounds: number
+  time_cap: string
+  type: "straight" | "superset" | "giant_set" | "circuit" | "emom" | "amrap"
+  updated_at: string
+}
+
+export interface ExerciseGroupInput {
+  created_at?: string
+  day_id?: string
+  id?: string
+  label: string
+  rest_between_rounds?: string
+  rounds?: number
+  time_cap?: string
+  type: "straight" | "superset" | "giant_set" | "circuit" | "emom" | "amrap"
+  updated_at?: string
+}
+
+export interface ExerciseInput {
+  created_at?: string
+  day_id?: string
+  group?: string
+  id?: string
+  load?: number
+  load_unit?: "kg" | "lb"
+  name?: string
+  percent_tm?: number
+  reps?: string
+  resolved?: ResolvedInput
+  rest?: string
+  rir?: number
+  rpe?: number
+  set_prescriptions?: SetPrescriptionInput[]
+  sets?: number
+  tempo?: string
+  updated_at?: string
+}
+
+export interface ForkProgramJSON {
+  shared_by: string
+}
+
+export interface Highlight {
+  entity_id: string
+  field: string
+  snippet: string
+}
+
+export interface InstantiateTemplateJSON {
+  days?: string[]
+  name?: string
+  shared_by: string
+  user_id?: string
+}
+
+export interface PatchProgramJSON {
+  days?: DayInput[]
+  name?: string
+  shared_by?: string
+}
+
+export interface Plate {
+  count: number
+  weight: number
+}
+
+export interface PlateInput {
+  count?: number
+  weight?: number
+}
+
+export interface PlateInventory {
+  bar: number
+  created_at: string
+  id: string
+  name: string
+  owner: string
+  plates: Plate[] | null
+  unit: string
+  updated_at: string
+}
+
+export interface Program {
+  created_at: string
+  days: Day[] | null
+  deleted_at: string | null
+  id: string
+  name: string
+  shared_by: string
+  updated_at: string
+  version: number
+}
+
+export interface ProgramStats {
+  days: number
+  exercises: number
+  groups: Record<string, number> | null
+  per_day: DayStats[] | null
+  program_id: string
+  sets: number
+}
+
+export interface PutTrainingMaxInput {
+  exercise: string
+  unit: "kg" | "lb"
+  user_id: string
+  value: number
+}
+
+export interface Resolved {
+  load: number
+  load_unit: string
+  training_max: number
+}
+
+export interface ResolvedInput {
+  load?: number
+  load_unit?: string
+  training_max?: number
+}
+
+export interface Result {
+  program: Program | null
+  rows: number
+  unmapped_exercises: string[] | null
+  workouts: number
+}
+
+export interface SearchHit {
+  highlights: Highlight[] | null
+  program: Program
+  score: number
+}
+
+export interface SessionDay {
+  day_id: string
+  exercises: SessionExercise[] | null
+  groups: ExerciseGroup[] | null
+  inventory: PlateInventory
+  name: string
+}
+
+export interface SessionExercise {
+  exercise_id: string
+  group: string
+  name: string
+  rest: string
+  sets: SessionSet[] | null
+}
+
+export interface SessionSet {
+  load: number | null
+  load_unit: string
+  number: number
+  plates: Breakdown | null
+  reps: string
+  rir: number | null
+  rpe: number | null
+  tempo: string
+  type: string
+}
+
+export interface Set {
+  load: number
+  percent: number
+  reps: number
+}
+
+export interface SetPrescription {
+  created_at: string
+  exercise_id: string
+  id: string
+  load: number | null
+  load_unit: "kg" | "lb" | ""
+  percent_tm: number | null
+  position: number
+  reps: string
+  resolved?: Resolved
+  rir: number | null
+  rpe: number | null
+  tempo: string
+  type: "warmup" | "working" | "drop" | "amrap"
+  updated_at: string
+}
+
+export interface SetPrescriptionInput {
+  created_at?: string
+  exercise_id?: string
+  id?: string
+  load?: number
+  load_unit?: "kg" | "lb"
+  percent_tm?: number
+  position?: number
+  reps: string
+  resolved?: ResolvedInput
+  rir?: number
+  rpe?: number
+  tempo?: string
+  type: "warmup" | "working" | "drop" | "amrap"
+  updated_at?: string
+}
+
+export interface Stats {
+  errors: number
+  hit_ratio: number
+  hits: number
+  misses: number
+}
+
+export interface Summary {
+  author: string
+  days_per_week: number
+  default_days: string[] | null
+  description: string
+  name: string
+  slug: string
+  training_maxes: string[] | null
+  version: number
+  weeks: number
+}
+
+export interface TrainingMax {
+  created_at: string
+  exercise: string
+  id: string
+  unit: string
+  updated_at: string
+  user_id: string
+  value: number
+}
+
+export interface UpdateProgramJSON {
+  days?: DayInput[]
+  name: string
+  shared_by: string
+}
+
+export interface WarmupExercise {
+  exercise_id: string
+  load_unit: string
+  name: string
+  sets: Set[] | null
+  working_load: number
+}
+
+export interface WebhookEndpoint {
+  active: boolean
+  created_at: string
+  events: string
+  id: string
+  updated_at: string
+  url: string
+}